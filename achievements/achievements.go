@@ -0,0 +1,181 @@
+package achievements
+
+import (
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event identifies what happened to trigger an achievement evaluation
+type Event string
+
+const (
+	EventSubmission     Event = "submission"
+	EventBattleFinished Event = "battle_finished"
+)
+
+// Rule describes an achievement and the condition a user must meet to earn it
+type Rule struct {
+	Code        string
+	Name        string
+	Description string
+	On          []Event
+	Check       func(db *gorm.DB, userID uint) (bool, error)
+}
+
+func (r Rule) triggeredBy(event Event) bool {
+	for _, e := range r.On {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules is the catalog of achievements, in display order
+var Rules = []Rule{
+	{
+		Code:        "first_solve",
+		Name:        "First Solve",
+		Description: "Answer a question correctly",
+		On:          []Event{EventSubmission},
+		Check:       solvedAtLeast(1),
+	},
+	{
+		Code:        "streak_10_days",
+		Name:        "On Fire",
+		Description: "Answer a question correctly on 10 consecutive days",
+		On:          []Event{EventSubmission},
+		Check:       streakAtLeast(10),
+	},
+	{
+		Code:        "hard_100",
+		Name:        "Hardened",
+		Description: "Solve 100 different advanced or expert questions",
+		On:          []Event{EventSubmission},
+		Check:       hardSolvedAtLeast(100),
+	},
+	{
+		Code:        "category_master",
+		Name:        "Category Master",
+		Description: "Solve 25 different questions in a single category",
+		On:          []Event{EventSubmission},
+		Check:       categorySolvedAtLeast(25),
+	},
+	{
+		Code:        "first_battle_win",
+		Name:        "Victor",
+		Description: "Win a room battle",
+		On:          []Event{EventBattleFinished},
+		Check:       battleWinsAtLeast(1),
+	},
+	{
+		Code:        "battle_wins_10",
+		Name:        "Champion",
+		Description: "Win 10 room battles",
+		On:          []Event{EventBattleFinished},
+		Check:       battleWinsAtLeast(10),
+	},
+}
+
+// Find returns the rule with the given code
+func Find(code string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.Code == code {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Evaluate checks every rule triggered by the event that the user has not yet earned,
+// and stores the ones that are now satisfied. It returns the newly earned achievements.
+func Evaluate(db *gorm.DB, userID uint, event Event) ([]models.UserAchievement, error) {
+	var earnedCodes []string
+	if err := db.Model(&models.UserAchievement{}).Where("user_id = ?", userID).Pluck("code", &earnedCodes).Error; err != nil {
+		return nil, err
+	}
+	earned := make(map[string]bool, len(earnedCodes))
+	for _, code := range earnedCodes {
+		earned[code] = true
+	}
+
+	var unlocked []models.UserAchievement
+	for _, rule := range Rules {
+		if earned[rule.Code] || !rule.triggeredBy(event) {
+			continue
+		}
+
+		ok, err := rule.Check(db, userID)
+		if err != nil {
+			return unlocked, err
+		}
+		if !ok {
+			continue
+		}
+
+		achievement := models.UserAchievement{UserID: userID, Code: rule.Code, EarnedAt: time.Now()}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievement)
+		if result.Error != nil {
+			return unlocked, result.Error
+		}
+		if result.RowsAffected > 0 {
+			unlocked = append(unlocked, achievement)
+		}
+	}
+
+	return unlocked, nil
+}
+
+// TakeUnannounced returns the user's achievements that have not yet been announced
+// and marks them as announced
+func TakeUnannounced(db *gorm.DB, userID uint) ([]models.UserAchievement, error) {
+	var pending []models.UserAchievement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_id = ? AND NOT announced", userID).
+			Order("earned_at").
+			Find(&pending).Error; err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(pending))
+		for i, a := range pending {
+			ids[i] = a.ID
+		}
+		return tx.Model(&models.UserAchievement{}).Where("id IN ?", ids).Update("announced", true).Error
+	})
+	return pending, err
+}
+
+// ListEarned returns all achievements earned by the user, oldest first
+func ListEarned(db *gorm.DB, userID uint) ([]models.UserAchievement, error) {
+	var earned []models.UserAchievement
+	err := db.Where("user_id = ?", userID).Order("earned_at").Find(&earned).Error
+	return earned, err
+}
+
+// View builds the JSON representation of an earned achievement
+func View(a models.UserAchievement) map[string]interface{} {
+	rule, _ := Find(a.Code)
+	return map[string]interface{}{
+		"code":        a.Code,
+		"name":        rule.Name,
+		"description": rule.Description,
+		"earnedAt":    a.EarnedAt,
+	}
+}
+
+// Views builds the JSON representation of a list of earned achievements
+func Views(list []models.UserAchievement) []map[string]interface{} {
+	views := make([]map[string]interface{}, len(list))
+	for i, a := range list {
+		views[i] = View(a)
+	}
+	return views
+}
@@ -0,0 +1,101 @@
+package achievements
+
+import (
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"gorm.io/gorm"
+)
+
+// solvedAtLeast is satisfied once the user has answered n different questions correctly
+func solvedAtLeast(n int64) func(db *gorm.DB, userID uint) (bool, error) {
+	return func(db *gorm.DB, userID uint) (bool, error) {
+		var count int64
+		err := db.Model(&models.Submission{}).
+			Where("user_id = ? AND is_correct", userID).
+			Distinct("question_id").
+			Count(&count).Error
+		return count >= n, err
+	}
+}
+
+// streakAtLeast is satisfied once the user has answered correctly on n consecutive (UTC) days
+func streakAtLeast(n int) func(db *gorm.DB, userID uint) (bool, error) {
+	return func(db *gorm.DB, userID uint) (bool, error) {
+		var days []time.Time
+		err := db.Raw(
+			"SELECT DISTINCT DATE(created_at AT TIME ZONE 'UTC') AS day FROM submissions WHERE user_id = ? AND is_correct ORDER BY day",
+			userID,
+		).Scan(&days).Error
+		if err != nil {
+			return false, err
+		}
+
+		return longestStreak(days) >= n, nil
+	}
+}
+
+// longestStreak returns the length of the longest run of consecutive UTC calendar days
+// in a sorted list of times. Several times on the same day count once.
+func longestStreak(days []time.Time) int {
+	longest, current := 0, 0
+	var previous time.Time
+	for i, t := range days {
+		day := t.UTC().Truncate(24 * time.Hour)
+		switch {
+		case i > 0 && day.Equal(previous):
+			continue
+		case i > 0 && day.Equal(previous.AddDate(0, 0, 1)):
+			current++
+		default:
+			current = 1
+		}
+		previous = day
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// hardSolvedAtLeast is satisfied once the user has solved n different advanced or expert questions
+func hardSolvedAtLeast(n int64) func(db *gorm.DB, userID uint) (bool, error) {
+	return func(db *gorm.DB, userID uint) (bool, error) {
+		var count int64
+		err := db.Model(&models.Submission{}).
+			Joins("JOIN questions ON questions.id = submissions.question_id").
+			Where("submissions.user_id = ? AND submissions.is_correct AND questions.difficulty IN ?", userID, []string{"advanced", "expert"}).
+			Distinct("submissions.question_id").
+			Count(&count).Error
+		return count >= n, err
+	}
+}
+
+// categorySolvedAtLeast is satisfied once the user has solved n different questions in any one category
+func categorySolvedAtLeast(n int64) func(db *gorm.DB, userID uint) (bool, error) {
+	return func(db *gorm.DB, userID uint) (bool, error) {
+		var best int64
+		err := db.Raw(`SELECT COUNT(DISTINCT submissions.question_id)
+			FROM submissions JOIN questions ON questions.id = submissions.question_id
+			WHERE submissions.user_id = ? AND submissions.is_correct
+			GROUP BY questions.category
+			ORDER BY 1 DESC
+			LIMIT 1`, userID).Scan(&best).Error
+		return best >= n, err
+	}
+}
+
+// battleWinsAtLeast is satisfied once the user has finished first in n room battles.
+// Ties share a rank, so a room only counts when nobody else also finished first.
+func battleWinsAtLeast(n int64) func(db *gorm.DB, userID uint) (bool, error) {
+	return func(db *gorm.DB, userID uint) (bool, error) {
+		var count int64
+		err := db.Model(&models.RoomParticipant{}).
+			Joins("JOIN rooms ON rooms.id = room_participants.room_id").
+			Where("room_participants.user_id = ? AND room_participants.rank = 1 AND rooms.status = ?", userID, models.RoomFinished).
+			Where(`NOT EXISTS (SELECT 1 FROM room_participants others
+				WHERE others.room_id = room_participants.room_id AND others.rank = 1 AND others.user_id <> room_participants.user_id)`).
+			Count(&count).Error
+		return count >= n, err
+	}
+}
@@ -0,0 +1,89 @@
+package achievements
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLongestStreak(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		days []string
+		want int
+	}{
+		{"no days", nil, 0},
+		{"one day", []string{"2024-03-01T00:00:00Z"}, 1},
+		{"consecutive days", []string{"2024-03-01T00:00:00Z", "2024-03-02T00:00:00Z", "2024-03-03T00:00:00Z"}, 3},
+		{"gap resets the run", []string{"2024-03-01T00:00:00Z", "2024-03-02T00:00:00Z", "2024-03-04T00:00:00Z"}, 2},
+		{"longest run is kept", []string{"2024-03-01T00:00:00Z", "2024-03-03T00:00:00Z", "2024-03-04T00:00:00Z", "2024-03-05T00:00:00Z", "2024-03-07T00:00:00Z"}, 3},
+		{"minutes apart across midnight", []string{"2024-03-01T23:59:00Z", "2024-03-02T00:01:00Z"}, 2},
+		{"same day counts once", []string{"2024-03-01T08:00:00Z", "2024-03-01T20:00:00Z", "2024-03-02T09:00:00Z"}, 2},
+		{"more than a day apart but consecutive dates", []string{"2024-03-01T00:10:00Z", "2024-03-02T23:50:00Z"}, 2},
+		{"under a day apart on dates two days apart", []string{"2024-03-01T23:00:00Z", "2024-03-03T00:30:00Z"}, 1},
+		{"month boundary", []string{"2024-01-31T00:00:00Z", "2024-02-01T00:00:00Z"}, 2},
+		{"leap day", []string{"2024-02-28T00:00:00Z", "2024-02-29T00:00:00Z", "2024-03-01T00:00:00Z"}, 3},
+		{"year boundary", []string{"2023-12-31T00:00:00Z", "2024-01-01T00:00:00Z"}, 2},
+		{"offsets are compared in UTC", []string{"2024-03-01T23:30:00-02:00", "2024-03-02T12:00:00Z"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := make([]time.Time, len(tt.days))
+			for i, d := range tt.days {
+				days[i] = at(d)
+			}
+			if got := longestStreak(days); got != tt.want {
+				t.Errorf("longestStreak = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rule := range Rules {
+		if seen[rule.Code] {
+			t.Errorf("rule code %s is used twice", rule.Code)
+		}
+		seen[rule.Code] = true
+		if rule.Name == "" || rule.Check == nil || len(rule.On) == 0 {
+			t.Errorf("rule %s needs a name, a check and at least one event", rule.Code)
+		}
+		if found, ok := Find(rule.Code); !ok || found.Code != rule.Code {
+			t.Errorf("Find(%q) did not return the rule", rule.Code)
+		}
+	}
+	if _, ok := Find("no_such_rule"); ok {
+		t.Error("Find returned a rule for an unknown code")
+	}
+}
+
+func TestTriggeredBy(t *testing.T) {
+	tests := []struct {
+		code  string
+		event Event
+		want  bool
+	}{
+		{"first_solve", EventSubmission, true},
+		{"first_solve", EventBattleFinished, false},
+		{"streak_10_days", EventSubmission, true},
+		{"first_battle_win", EventBattleFinished, true},
+		{"battle_wins_10", EventSubmission, false},
+	}
+	for _, tt := range tests {
+		rule, ok := Find(tt.code)
+		if !ok {
+			t.Fatalf("rule %s not found", tt.code)
+		}
+		if got := rule.triggeredBy(tt.event); got != tt.want {
+			t.Errorf("%s triggered by %s = %v, want %v", tt.code, tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/logging"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/secrets"
+	"github.com/joho/godotenv"
+)
+
+// Config holds every setting the server reads at startup
+type Config struct {
+	Port int
+	// PublicURL is the externally reachable base URL (HOST), used in emailed links and OAuth redirects
+	PublicURL string
+
+	Server   ServerConfig
+	Database DatabaseConfig
+	Identity IdentityConfig
+	Mail     MailConfig
+	Tracing  TracingConfig
+
+	GeminiAPIKey       string
+	GoogleClientID     string
+	GoogleClientSecret string
+
+	// RequireVerifiedEmail lists the actions that need a verified email address
+	RequireVerifiedEmail []string
+	// AccountDeletionPolicy is "anonymize" or "delete"
+	AccountDeletionPolicy string
+
+	LogLevel  slog.Level
+	LogFormat string // "json" or "text"
+}
+
+// ServerConfig sets the HTTP server timeouts and the internal metrics listener
+type ServerConfig struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout bounds the drain of in-flight requests and the release of resources on SIGTERM
+	ShutdownTimeout time.Duration
+	// MetricsAddr is the host:port serving /metrics, apart from the public API; empty disables it
+	MetricsAddr string
+}
+
+type DatabaseConfig struct {
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	Name        string
+	AutoMigrate bool
+}
+
+type IdentityConfig struct {
+	Provider                string // "firebase" or "local"
+	FirebaseCredentialsFile string
+	FirebaseWebAPIKey       string
+	LocalSecret             string
+	LocalStore              string
+}
+
+type MailConfig struct {
+	Sender       string // "capture" or "smtp"
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// TracingConfig selects where OpenTelemetry spans are sent
+type TracingConfig struct {
+	Exporter     string // "none", "stdout" or "otlp"
+	OTLPEndpoint string
+	SampleRatio  float64
+}
+
+// Current is the configuration the server runs with. It holds the defaults until
+// main replaces it with the result of Load.
+var Current = Default()
+
+// setting is a configuration key with its default. The key is used as the environment
+// variable and config file name; the flag is the key in lower case with dashes.
+// Secrets are listed separately in secretSettings.
+type setting struct {
+	key   string
+	value string
+	usage string
+}
+
+var settings = []setting{
+	{"PORT", "8080", "port the HTTP server listens on"},
+	{"HOST", "http://localhost:8080", "public base URL used in emailed links and OAuth redirects"},
+	{"HTTP_READ_TIMEOUT", "15s", "limit for reading a request, including the body"},
+	{"HTTP_WRITE_TIMEOUT", "60s", "limit for handling a request and writing the response; covers question generation"},
+	{"HTTP_IDLE_TIMEOUT", "120s", "how long idle keep-alive connections stay open"},
+	{"SHUTDOWN_TIMEOUT", "30s", "how long SIGTERM waits for in-flight requests and cleanup"},
+	{"METRICS_ADDR", ":9090", "internal host:port serving /metrics; never publish this port, or leave empty to disable"},
+
+	{"POSTGRES_HOST", "localhost", "database host"},
+	{"POSTGRES_PORT", "5432", "database port"},
+	{"POSTGRES_USER", "postgres", "database user"},
+	{"POSTGRES_DB", "thinkbattleground", "database name"},
+	{"AUTO_MIGRATE", "true", "apply pending migrations at startup"},
+
+	{"IDENTITY_PROVIDER", "firebase", "identity provider: firebase or local"},
+	{"FIREBASE_CREDENTIALS_FILE", "config/firebase-service-account.json", "Firebase service account key file"},
+	{"LOCAL_AUTH_STORE", "", "file the local provider keeps its accounts in (memory only when empty)"},
+
+	{"MAIL_SENDER", "", "email sender, required to serve: smtp, or capture (keeps mail in memory; local development only)"},
+	{"SMTP_HOST", "", "SMTP server host"},
+	{"SMTP_PORT", "587", "SMTP server port"},
+	{"SMTP_USERNAME", "", "SMTP user"},
+	{"SMTP_FROM", "", "sender address for outgoing email"},
+
+	{"GOOGLE_CLIENT_ID", "", "OAuth client ID for Google sign-in"},
+
+	{"REQUIRE_VERIFIED_EMAIL", "", "comma-separated actions that need a verified email: ranked, admin"},
+	{"ACCOUNT_DELETION_POLICY", "anonymize", "what account deletion does to stored data: anonymize or delete"},
+
+	{"SECRETS_DIR", "/run/secrets", "directory of mounted secret files, one per secret"},
+
+	{"LOG_LEVEL", "info", "minimum log level: debug, info, warn or error; debug includes SQL queries"},
+	{"LOG_FORMAT", "json", "log format: json or text"},
+
+	{"TRACING_EXPORTER", "none", "where traces are sent: none, stdout (local debugging) or otlp"},
+	{"TRACING_OTLP_ENDPOINT", "http://localhost:4318", "OTLP/HTTP collector URL used when TRACING_EXPORTER is otlp"},
+	{"TRACING_SAMPLE_RATIO", "1", "share of new traces recorded, from 0 to 1"},
+}
+
+// secretSettings are never taken from flags, which other users can read from the process
+// list. Each is looked up as an environment variable, then in the file named by its _FILE
+// variable, then in SECRETS_DIR, then in the config file.
+var secretSettings = []string{
+	"POSTGRES_PASSWORD",
+	"FIREBASE_WEB_API_KEY",
+	"LOCAL_AUTH_SECRET", // at least 32 bytes
+	"SMTP_PASSWORD",
+	"GEMINI_API_KEY",
+	"GOOGLE_CLIENT_SECRET",
+}
+
+// ValidationError lists every problem found in the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.key] = s.value
+	}
+	cfg, err := parse(values)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// Load reads the configuration from, in decreasing precedence, command-line flags,
+// environment variables, a file of KEY=value lines and the defaults. The file is the
+// -config flag or CONFIG_FILE, falling back to .env when it exists. Load returns the
+// arguments left after the flags, and a *ValidationError listing all invalid settings.
+func Load(args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet("thinkbattleground", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "file of KEY=value settings (default .env when present)")
+	flagKeys := make(map[string]string, len(settings))
+	for _, s := range settings {
+		name := strings.ToLower(strings.ReplaceAll(s.key, "_", "-"))
+		flags.String(name, s.value, s.usage+" ("+s.key+")")
+		flagKeys[name] = s.key
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.key] = s.value
+	}
+
+	fileValues, err := readConfigFile(*file)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, s := range settings {
+		if value, ok := fileValues[s.key]; ok && value != "" {
+			values[s.key] = value
+		}
+	}
+
+	for _, s := range settings {
+		if value := os.Getenv(s.key); value != "" {
+			values[s.key] = value
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			values[key] = f.Value.String()
+		}
+	})
+
+	var problems []string
+	source := secrets.Chain(secrets.Env(), secrets.File(), secrets.Dir(values["SECRETS_DIR"]), secrets.Map(fileValues))
+	for _, key := range secretSettings {
+		value, _, err := source.Lookup(key)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		values[key] = value
+	}
+
+	cfg, err := parse(values)
+	if len(problems) > 0 {
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			problems = append(problems, invalid.Problems...)
+		}
+		err = &ValidationError{Problems: problems}
+	}
+	return cfg, flags.Args(), err
+}
+
+// Secrets returns every secret value in the configuration, for redacting logs
+func (c *Config) Secrets() []string {
+	return []string{
+		c.Database.Password,
+		c.Identity.FirebaseWebAPIKey,
+		c.Identity.LocalSecret,
+		c.Mail.SMTPPassword,
+		c.GeminiAPIKey,
+		c.GoogleClientSecret,
+	}
+}
+
+// CheckSecrets reports every secret a configured feature needs but was not given. The
+// migrate and reconcile-roles commands skip it since they call neither Firebase nor Google.
+// GEMINI_API_KEY is optional: without it question generation is disabled.
+func (c *Config) CheckSecrets() error {
+	var missing []string
+	if c.Identity.Provider == "firebase" && c.Identity.FirebaseWebAPIKey == "" {
+		missing = append(missing, "FIREBASE_WEB_API_KEY is required when IDENTITY_PROVIDER is firebase")
+	}
+	if (c.GoogleClientID == "") != (c.GoogleClientSecret == "") {
+		missing = append(missing, "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET must be set together")
+	}
+	// Without an explicit sender a deployment would silently send no email
+	if c.Mail.Sender == "" {
+		missing = append(missing, "MAIL_SENDER must be set: smtp, or capture for local development")
+	}
+	if c.Mail.Sender == "smtp" && c.Mail.SMTPUsername != "" && c.Mail.SMTPPassword == "" {
+		missing = append(missing, "SMTP_PASSWORD is required when SMTP_USERNAME is set")
+	}
+
+	if len(missing) > 0 {
+		return &ValidationError{Problems: missing}
+	}
+	return nil
+}
+
+// readConfigFile reads a dotenv-style file. A missing .env is not an error,
+// since deployments usually set real environment variables instead.
+func readConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		values, err := godotenv.Read(".env")
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .env: %w", err)
+		}
+		return values, nil
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parse converts the raw values into a Config, collecting every problem instead of
+// stopping at the first
+func parse(values map[string]string) (*Config, error) {
+	p := &parser{values: values}
+
+	cfg := &Config{
+		Port:      p.port("PORT"),
+		PublicURL: strings.TrimSuffix(p.baseURL("HOST"), "/"),
+		Server: ServerConfig{
+			ReadTimeout:     p.duration("HTTP_READ_TIMEOUT"),
+			WriteTimeout:    p.duration("HTTP_WRITE_TIMEOUT"),
+			IdleTimeout:     p.duration("HTTP_IDLE_TIMEOUT"),
+			ShutdownTimeout: p.duration("SHUTDOWN_TIMEOUT"),
+			MetricsAddr:     p.optionalAddr("METRICS_ADDR"),
+		},
+		Database: DatabaseConfig{
+			Host:        values["POSTGRES_HOST"],
+			Port:        p.port("POSTGRES_PORT"),
+			User:        values["POSTGRES_USER"],
+			Password:    values["POSTGRES_PASSWORD"],
+			Name:        values["POSTGRES_DB"],
+			AutoMigrate: p.bool("AUTO_MIGRATE"),
+		},
+		Identity: IdentityConfig{
+			Provider:                p.oneOf("IDENTITY_PROVIDER", "firebase", "local"),
+			FirebaseCredentialsFile: values["FIREBASE_CREDENTIALS_FILE"],
+			FirebaseWebAPIKey:       values["FIREBASE_WEB_API_KEY"],
+			LocalSecret:             values["LOCAL_AUTH_SECRET"],
+			LocalStore:              values["LOCAL_AUTH_STORE"],
+		},
+		Mail: MailConfig{
+			Sender:       p.optionalOneOf("MAIL_SENDER", "capture", "smtp"),
+			SMTPHost:     values["SMTP_HOST"],
+			SMTPPort:     p.port("SMTP_PORT"),
+			SMTPUsername: values["SMTP_USERNAME"],
+			SMTPPassword: values["SMTP_PASSWORD"],
+			SMTPFrom:     values["SMTP_FROM"],
+		},
+		Tracing: TracingConfig{
+			Exporter:     p.oneOf("TRACING_EXPORTER", "none", "stdout", "otlp"),
+			OTLPEndpoint: p.baseURL("TRACING_OTLP_ENDPOINT"),
+			SampleRatio:  p.ratio("TRACING_SAMPLE_RATIO"),
+		},
+		GeminiAPIKey:          values["GEMINI_API_KEY"],
+		GoogleClientID:        values["GOOGLE_CLIENT_ID"],
+		GoogleClientSecret:    values["GOOGLE_CLIENT_SECRET"],
+		RequireVerifiedEmail:  p.list("REQUIRE_VERIFIED_EMAIL", "ranked", "admin"),
+		AccountDeletionPolicy: p.oneOf("ACCOUNT_DELETION_POLICY", "anonymize", "delete"),
+		LogLevel:              p.logLevel("LOG_LEVEL"),
+		LogFormat:             p.oneOf("LOG_FORMAT", "json", "text"),
+	}
+
+	if cfg.Identity.Provider == "local" && len(cfg.Identity.LocalSecret) < 32 {
+		p.problem("LOCAL_AUTH_SECRET must be at least 32 bytes when IDENTITY_PROVIDER is local")
+	}
+	if cfg.Mail.Sender == "smtp" && (cfg.Mail.SMTPHost == "" || cfg.Mail.SMTPFrom == "") {
+		p.problem("SMTP_HOST and SMTP_FROM are required when MAIL_SENDER is smtp")
+	}
+
+	if len(p.problems) > 0 {
+		return cfg, &ValidationError{Problems: p.problems}
+	}
+	return cfg, nil
+}
+
+type parser struct {
+	values   map[string]string
+	problems []string
+}
+
+func (p *parser) problem(format string, args ...interface{}) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) port(key string) int {
+	port, err := strconv.Atoi(p.values[key])
+	if err != nil || port < 1 || port > 65535 {
+		p.problem("%s must be a port number, got %q", key, p.values[key])
+	}
+	return port
+}
+
+// optionalAddr reads a host:port listen address that may be left empty
+func (p *parser) optionalAddr(key string) string {
+	value := p.values[key]
+	if value == "" {
+		return ""
+	}
+	if _, port, err := net.SplitHostPort(value); err != nil || port == "" {
+		p.problem("%s must be a host:port address such as localhost:9090, got %q", key, value)
+	}
+	return value
+}
+
+func (p *parser) duration(key string) time.Duration {
+	value, err := time.ParseDuration(p.values[key])
+	if err != nil || value <= 0 {
+		p.problem("%s must be a positive duration such as 30s, got %q", key, p.values[key])
+	}
+	return value
+}
+
+func (p *parser) ratio(key string) float64 {
+	value, err := strconv.ParseFloat(p.values[key], 64)
+	if err != nil || value < 0 || value > 1 {
+		p.problem("%s must be a number from 0 to 1, got %q", key, p.values[key])
+	}
+	return value
+}
+
+func (p *parser) logLevel(key string) slog.Level {
+	level, err := logging.ParseLevel(p.values[key])
+	if err != nil {
+		p.problem("%s must be debug, info, warn or error, got %q", key, p.values[key])
+	}
+	return level
+}
+
+func (p *parser) bool(key string) bool {
+	value, err := strconv.ParseBool(p.values[key])
+	if err != nil {
+		p.problem("%s must be true or false, got %q", key, p.values[key])
+	}
+	return value
+}
+
+func (p *parser) oneOf(key string, options ...string) string {
+	value := p.values[key]
+	for _, option := range options {
+		if value == option {
+			return value
+		}
+	}
+	p.problem("%s must be one of %s, got %q", key, strings.Join(options, ", "), value)
+	return value
+}
+
+// optionalOneOf is oneOf for a setting that may be left empty
+func (p *parser) optionalOneOf(key string, options ...string) string {
+	if p.values[key] == "" {
+		return ""
+	}
+	return p.oneOf(key, options...)
+}
+
+// list splits a comma-separated value, rejecting entries outside options
+func (p *parser) list(key string, options ...string) []string {
+	var items []string
+	for _, item := range strings.Split(p.values[key], ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		known := false
+		for _, option := range options {
+			known = known || item == option
+		}
+		if !known {
+			p.problem("%s has unknown entry %q (expected %s)", key, item, strings.Join(options, ", "))
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (p *parser) baseURL(key string) string {
+	value := p.values[key]
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.problem("%s must be an absolute http or https URL, got %q", key, value)
+	}
+	return value
+}
@@ -8,9 +8,9 @@ import (
 	"google.golang.org/api/option"
 )
 
-func InitializeFirebase() *firebase.App {
-	// You need to download your Firebase service account key JSON file and place it in the config directory
-	opt := option.WithCredentialsFile("config/firebase-service-account.json")
+// InitializeFirebase opens the Firebase app with the service account key at credentialsFile
+func InitializeFirebase(credentialsFile string) *firebase.App {
+	opt := option.WithCredentialsFile(credentialsFile)
 
 	app, err := firebase.NewApp(context.Background(), nil, opt)
 	if err != nil {
@@ -4,36 +4,58 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
 var GeminiClient *genai.Client
 
-// InitializeGemini initializes the Gemini AI client using GEMINI_API_KEY.
-func InitializeGemini() error {
-	apiKey := os.Getenv("GEMINI_API_KEY")
+// geminiHTTPClient carries the Gemini client's connections so CloseGemini can release them
+var geminiHTTPClient *http.Client
+
+// InitializeGemini initializes the Gemini AI client with the GEMINI_API_KEY setting.
+func InitializeGemini(apiKey string) error {
 	if apiKey == "" {
-		return fmt.Errorf("GEMINI_API_KEY environment variable is not set")
+		return fmt.Errorf("GEMINI_API_KEY is not set")
 	}
 
 	ctx := context.Background()
 
-	client, err := genai.NewClient(ctx, nil) // reads GEMINI_API_KEY automatically
+	httpClient := &http.Client{}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey, Backend: genai.BackendGeminiAPI, HTTPClient: httpClient})
 	if err != nil {
 		return fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
 	GeminiClient = client
+	geminiHTTPClient = httpClient
 	return nil
 }
 
+// CloseGemini drops the Gemini client and closes its idle connections. Call it only
+// once no request can still be generating a question.
+func CloseGemini() {
+	GeminiClient = nil
+	if geminiHTTPClient != nil {
+		geminiHTTPClient.CloseIdleConnections()
+	}
+}
+
+// geminiModel is the model questions are generated with
+const geminiModel = "gemini-2.5-flash"
+
 // GenerateQuestion generates a math question using Gemini API (new GenAI SDK)
 func GenerateQuestion(ctx context.Context, category string, difficulty string) (map[string]interface{}, error) {
 	if GeminiClient == nil {
-		return nil, fmt.Errorf("gemini client not initialized")
+		return nil, ErrGeneratorDisabled
 	}
 
 	systemTemplate := `You are a math problem generator.`
@@ -50,9 +72,19 @@ Keep answer and explanation brief. Return ONLY valid JSON with these exact field
 	var temperature float32 = 0.4
 	zero := int32(0) // disable thinking
 
+	ctx, span := tracing.Tracer.Start(ctx, "gemini.GenerateContent", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("gen_ai.system", "gemini"),
+			attribute.String("gen_ai.request.model", geminiModel),
+			attribute.String("question.category", category),
+			attribute.String("question.difficulty", difficulty),
+		))
+	defer span.End()
+
+	began := time.Now()
 	resp, err := GeminiClient.Models.GenerateContent(
 		ctx,
-		"gemini-2.5-flash",
+		geminiModel,
 		genai.Text(humanTemplate),
 		&genai.GenerateContentConfig{
 			MaxOutputTokens:  maxTokens,
@@ -69,38 +101,59 @@ Keep answer and explanation brief. Return ONLY valid JSON with these exact field
 			},
 		},
 	)
+	elapsed := time.Since(began)
+	metrics.GeminiDuration.Observe(elapsed.Seconds())
+	attrs := []any{"category", category, "difficulty", difficulty, "duration_ms", float64(elapsed.Microseconds()) / 1000}
 	if err != nil {
+		metrics.GeminiRequests.WithLabelValues("error").Inc()
+		tracing.RecordError(span, err)
+		slog.ErrorContext(ctx, "Gemini request failed", append(attrs, "error", err)...)
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
+	if resp != nil && resp.UsageMetadata != nil {
+		metrics.GeminiTokens.WithLabelValues("prompt").Add(float64(resp.UsageMetadata.PromptTokenCount))
+		metrics.GeminiTokens.WithLabelValues("output").Add(float64(resp.UsageMetadata.CandidatesTokenCount))
+		span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", int(resp.UsageMetadata.PromptTokenCount)),
+			attribute.Int("gen_ai.usage.output_tokens", int(resp.UsageMetadata.CandidatesTokenCount)),
+		)
+		attrs = append(attrs, "prompt_tokens", resp.UsageMetadata.PromptTokenCount, "output_tokens", resp.UsageMetadata.CandidatesTokenCount)
+	}
+	slog.InfoContext(ctx, "Gemini request", attrs...)
+
+	// A response that does not hold a usable question is counted apart from API errors
+	outcome := "invalid_response"
+	defer func() { metrics.GeminiRequests.WithLabelValues(outcome).Inc() }()
 
 	if resp == nil {
 		return nil, fmt.Errorf("nil response from Gemini API")
 	}
 
-	 // Combine / clean output
-    text := strings.TrimSpace(resp.Text())
-    if text == "" {
-        return nil, fmt.Errorf("empty response from Gemini API")
-    }
-
-    // Remove possible ```json fences
-    text = strings.TrimPrefix(text, "```json")
-    text = strings.TrimPrefix(text, "```")
-    text = strings.TrimSuffix(text, "```")
-    text = strings.TrimSpace(text)
-
-    // If response has extra content, try to isolate JSON
-    start := strings.Index(text, "{")
-    end := strings.LastIndex(text, "}")
-    if start != -1 && end != -1 && end > start {
-        text = text[start : end+1]
-    }
-
-	fmt.Println("Generated Question JSON:", text)
-    var obj map[string]interface{}
-    if err := json.Unmarshal([]byte(text), &obj); err != nil {
-        return nil, fmt.Errorf("failed to parse JSON: %w\nraw: %s", err, text)
-    }
-
-    return obj, nil
+	// Combine / clean output
+	text := strings.TrimSpace(resp.Text())
+	if text == "" {
+		return nil, fmt.Errorf("empty response from Gemini API")
+	}
+
+	// Remove possible ```json fences
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+
+	// If response has extra content, try to isolate JSON
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start != -1 && end != -1 && end > start {
+		text = text[start : end+1]
+	}
+
+	slog.DebugContext(ctx, "Generated question JSON", "json", text)
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w\nraw: %s", err, text)
+	}
+
+	outcome = "ok"
+	return obj, nil
 }
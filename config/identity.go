@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+)
+
+// InitializeIdentityProvider builds the identity provider selected by IDENTITY_PROVIDER.
+// "firebase" (the default) uses the Firebase project; "local" signs its own tokens with
+// LOCAL_AUTH_SECRET and needs no network access, for development and integration tests.
+func InitializeIdentityProvider(cfg IdentityConfig) (identity.IdentityProvider, error) {
+	switch cfg.Provider {
+	case "firebase":
+		app := InitializeFirebase(cfg.FirebaseCredentialsFile)
+		if app == nil {
+			return nil, fmt.Errorf("error initializing Firebase")
+		}
+		return identity.NewFirebaseProvider(context.Background(), app, cfg.FirebaseWebAPIKey)
+	case "local":
+		return identity.NewLocalProvider([]byte(cfg.LocalSecret), cfg.LocalStore)
+	default:
+		return nil, fmt.Errorf("unknown IDENTITY_PROVIDER %q (expected firebase or local)", cfg.Provider)
+	}
+}
+
+// CheckIdentity reports whether the identity provider is configured well enough to
+// verify tokens and sign users in
+func CheckIdentity(cfg IdentityConfig) error {
+	switch cfg.Provider {
+	case "firebase":
+		if cfg.FirebaseWebAPIKey == "" {
+			return fmt.Errorf("FIREBASE_WEB_API_KEY is not set")
+		}
+		if _, err := os.Stat(cfg.FirebaseCredentialsFile); err != nil {
+			return fmt.Errorf("service account key unavailable: %w", err)
+		}
+		return nil
+	case "local":
+		if len(cfg.LocalSecret) < 32 {
+			return fmt.Errorf("LOCAL_AUTH_SECRET is shorter than 32 bytes")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown IDENTITY_PROVIDER %q", cfg.Provider)
+	}
+}
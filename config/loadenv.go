@@ -1,24 +0,0 @@
-package config
-
-import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
-)
-
-func LoadEnv() error {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Println("Error: ", err)
-		return err
-	}
-	return nil
-}
-
-func GetEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
\ No newline at end of file
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/mailer"
+)
+
+// InitializeMailer builds the email sender selected by MAIL_SENDER.
+// "capture" (the default) logs messages instead of sending them; "smtp" uses the SMTP_* settings.
+func InitializeMailer(cfg MailConfig) (mailer.Sender, error) {
+	switch cfg.Sender {
+	case "capture":
+		return mailer.NewCaptureSender(true), nil
+	case "smtp":
+		return mailer.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_SENDER %q (expected capture or smtp)", cfg.Sender)
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrGeneratorDisabled is returned when no GEMINI_API_KEY was configured
+var ErrGeneratorDisabled = errors.New("question generation is disabled: GEMINI_API_KEY is not set")
+
+// QuestionGenerator creates a question as a map in the JSON shape GenerateQuestion returns
+type QuestionGenerator interface {
+	GenerateQuestion(ctx context.Context, category, difficulty string) (map[string]interface{}, error)
+}
+
+// GeminiGenerator generates questions with the client set up by InitializeGemini
+type GeminiGenerator struct{}
+
+// GenerateQuestion calls the Gemini API
+func (GeminiGenerator) GenerateQuestion(ctx context.Context, category, difficulty string) (map[string]interface{}, error) {
+	return GenerateQuestion(ctx, category, difficulty)
+}
+
+// Ready reports whether InitializeGemini has set up the client
+func (GeminiGenerator) Ready() error {
+	if GeminiClient == nil {
+		return ErrGeneratorDisabled
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Account deletion policies, selected by ACCOUNT_DELETION_POLICY
+const (
+	// deletionAnonymize strips personal data from the user row and soft-deletes it, keeping
+	// submissions and battle results so scoreboards and opponents' histories stay intact
+	deletionAnonymize = "anonymize"
+	// deletionPurge removes the user row and everything that references it
+	deletionPurge = "delete"
+)
+
+// DeleteAccount permanently deletes the caller's account from the identity provider
+// and anonymizes or removes their data according to ACCOUNT_DELETION_POLICY
+func (ac *AuthController) DeleteAccount(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	policy := config.Current.AccountDeletionPolicy
+	ctx := c.Request.Context()
+
+	// Disable the identity account and revoke its tokens before touching any data, so no
+	// session outlives the deletion. A missing account means a previous attempt deleted it.
+	if err := ac.provider.SetDisabled(ctx, user.FirebaseUID, true); err != nil && !errors.Is(err, identity.ErrUserNotFound) {
+		slog.ErrorContext(ctx, "Failed to disable account for deletion", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if err := ac.provider.RevokeRefreshTokens(ctx, user.FirebaseUID); err != nil && !errors.Is(err, identity.ErrUserNotFound) {
+		slog.ErrorContext(ctx, "Failed to revoke tokens for deletion", "user_id", user.ID, "error", err)
+		ac.reenableAfterFailedDeletion(ctx, user)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	if err := ac.store.Users().DeleteAccount(ctx, user.ID, policy == deletionPurge); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete account data", "user_id", user.ID, "error", err)
+		ac.reenableAfterFailedDeletion(ctx, user)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	// The data is gone and the disabled account can no longer sign in, so a failure here
+	// only leaves an inert identity account for an operator to remove
+	if err := ac.provider.DeleteUser(ctx, user.FirebaseUID); err != nil && !errors.Is(err, identity.ErrUserNotFound) {
+		slog.ErrorContext(ctx, "Failed to delete identity account after deleting its data",
+			"user_id", user.ID, "uid", user.FirebaseUID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted",
+		"policy":  policy,
+	})
+}
+
+// reenableAfterFailedDeletion undoes the disable step of DeleteAccount so the user can
+// sign in again and retry
+func (ac *AuthController) reenableAfterFailedDeletion(ctx context.Context, user models.User) {
+	if err := ac.provider.SetDisabled(ctx, user.FirebaseUID, false); err != nil {
+		slog.ErrorContext(ctx, "Failed to re-enable account after a failed deletion",
+			"user_id", user.ID, "uid", user.FirebaseUID, "error", err)
+	}
+}
+
+// ExportAccountData returns everything stored about the caller. The default is a JSON
+// document; ?format=zip returns the same data as one JSON file per section.
+func (ac *AuthController) ExportAccountData(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	export, err := collectUserData(c.Request.Context(), ac.store, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account data"})
+		return
+	}
+
+	filename := fmt.Sprintf("thinkbattleground-export-%d", user.ID)
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for name, section := range export {
+		w, err := archive.Create(name + ".json")
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to write export archive", "error", err)
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to write export archive", "error", err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to write export archive", "error", err)
+	}
+}
+
+// collectUserData gathers every record that belongs to the user, keyed by section name.
+// Secrets such as token hashes are left out.
+func collectUserData(ctx context.Context, store repository.Store, user models.User) (gin.H, error) {
+	records, err := store.Exports().UserRecords(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	earned, err := store.Achievements().ListEarned(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := store.Users().Roles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	submissionViews := make([]gin.H, 0, len(records.Submissions))
+	for _, s := range records.Submissions {
+		submissionViews = append(submissionViews, gin.H{
+			"questionId":  s.QuestionID,
+			"contestId":   s.ContestID,
+			"roomId":      s.RoomID,
+			"answer":      s.Answer,
+			"isCorrect":   s.IsCorrect,
+			"points":      s.Points,
+			"submittedAt": s.CreatedAt,
+		})
+	}
+
+	registrationViews := make([]gin.H, 0, len(records.Registrations))
+	for _, r := range records.Registrations {
+		registrationViews = append(registrationViews, gin.H{
+			"contestId":    r.ContestID,
+			"registeredAt": r.CreatedAt,
+		})
+	}
+
+	roomViews := make([]gin.H, 0, len(records.Participants))
+	for _, p := range records.Participants {
+		roomViews = append(roomViews, gin.H{
+			"roomId":       p.RoomID,
+			"score":        p.Score,
+			"correct":      p.Correct,
+			"rank":         p.Rank,
+			"ratingChange": p.RatingChange,
+			"joinedAt":     p.CreatedAt,
+		})
+	}
+
+	friendshipViews := make([]gin.H, 0, len(records.Friendships))
+	for _, f := range records.Friendships {
+		friendshipViews = append(friendshipViews, gin.H{
+			"requesterId": f.RequesterID,
+			"addresseeId": f.AddresseeID,
+			"status":      f.Status,
+			"createdAt":   f.CreatedAt,
+			"acceptedAt":  f.AcceptedAt,
+		})
+	}
+
+	blockViews := make([]gin.H, 0, len(records.Blocks))
+	for _, b := range records.Blocks {
+		blockViews = append(blockViews, gin.H{
+			"userId":    b.BlockedID,
+			"blockedAt": b.CreatedAt,
+		})
+	}
+
+	sessionViews := make([]gin.H, 0, len(records.Sessions))
+	for _, s := range records.Sessions {
+		view := sessionView(s)
+		view["revokedAt"] = s.RevokedAt
+		sessionViews = append(sessionViews, view)
+	}
+
+	return gin.H{
+		"profile": gin.H{
+			"id":            user.ID,
+			"uid":           user.FirebaseUID,
+			"email":         user.Email,
+			"emailVerified": user.EmailVerified,
+			"displayName":   user.DisplayName,
+			"photoURL":      user.PhotoURL,
+			"phone":         user.Phone,
+			"country":       user.Country,
+			"bio":           user.Bio,
+			"isAdmin":       user.IsAdmin,
+			"rating":        user.Rating,
+			"roles":         roles,
+			"privacy":       user.PrivacySettings(),
+			"createdAt":     user.CreatedAt,
+			"updatedAt":     user.UpdatedAt,
+			"exportedAt":    time.Now(),
+		},
+		"submissions":          submissionViews,
+		"contestRegistrations": registrationViews,
+		"rooms":                roomViews,
+		"achievements":         achievements.Views(earned),
+		"friendships":          friendshipViews,
+		"blocks":               blockViews,
+		"sessions":             sessionViews,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type AchievementController struct {
+	store repository.Store
+}
+
+// NewAchievementController creates a new instance of AchievementController
+func NewAchievementController(store repository.Store) *AchievementController {
+	return &AchievementController{store: store}
+}
+
+// ListAchievements returns the achievement catalog with the caller's progress
+func (ac *AchievementController) ListAchievements(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	earned, err := ac.store.Achievements().ListEarned(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve achievements"})
+		return
+	}
+	earnedAt := make(map[string]interface{}, len(earned))
+	for _, a := range earned {
+		earnedAt[a.Code] = a.EarnedAt
+	}
+
+	catalog := make([]gin.H, len(achievements.Rules))
+	for i, rule := range achievements.Rules {
+		_, ok := earnedAt[rule.Code]
+		catalog[i] = gin.H{
+			"code":        rule.Code,
+			"name":        rule.Name,
+			"description": rule.Description,
+			"earned":      ok,
+			"earnedAt":    earnedAt[rule.Code],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":        len(catalog),
+		"achievements": catalog,
+	})
+}
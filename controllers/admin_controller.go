@@ -1,14 +1,30 @@
 package controllers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
+	"time"
 
-	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
 	"github.com/gin-gonic/gin"
 )
 
-type AdminController struct{}
+type AdminController struct {
+	provider identity.IdentityProvider
+	store    repository.Store
+}
+
+// NewAdminController creates a new instance of AdminController
+func NewAdminController(provider identity.IdentityProvider, store repository.Store) *AdminController {
+	return &AdminController{
+		provider: provider,
+		store:    store,
+	}
+}
 
 // MakeUserAdmin promotes a user to admin status
 func (ac *AdminController) MakeUserAdmin(c *gin.Context) {
@@ -21,18 +37,44 @@ func (ac *AdminController) MakeUserAdmin(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := database.DB.Where("firebase_uid = ?", input.UserID).First(&user).Error; err != nil {
+	ctx := c.Request.Context()
+	user, err := ac.store.Users().FindByUID(ctx, input.UserID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
+	if err := checkVerified(user, verifiedForAdmin); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User must verify their email address before being promoted"})
+		return
+	}
 
-	// Update user to admin
-	user.IsAdmin = true
-	if err := database.DB.Save(&user).Error; err != nil {
+	// Grant the admin role, which also sets IsAdmin; promoting an admin again is not an error
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	err = ac.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := userSnapshot(c, tx.Users(), user.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Users().GrantRole(ctx, actor.ID, user.ID, rbac.RoleAdmin); err != nil {
+			if errors.Is(err, rbac.ErrRoleHeld) {
+				return nil // nothing changed, so nothing to audit
+			}
+			return err
+		}
+		after, err := userSnapshot(c, tx.Users(), user.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), actor, auditUserPromote, "user", user.ID, before, after)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
+	user.IsAdmin = true
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User promoted to admin successfully",
@@ -41,53 +83,36 @@ func (ac *AdminController) MakeUserAdmin(c *gin.Context) {
 			"email":   user.Email,
 			"isAdmin": user.IsAdmin,
 		},
+		"claimsSynced": syncRoleClaims(c, ac.provider, ac.store.Users(), user),
 	})
 }
 
 // UpdateUserProfile updates additional user information
 func (ac *AuthController) UpdateUserProfile(c *gin.Context) {
 	var input struct {
-		UserID      string  `json:"userId"`
 		DisplayName *string `json:"displayName"`
 		Phone       *string `json:"phone"`
 		Country     *string `json:"country"`
 		Bio         *string `json:"bio"`
 	}
-	userId, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not authenticated",
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	input.UserID = userId.(string)
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.Where("firebase_uid = ?", input.UserID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
 	// Update only provided fields
-	if input.DisplayName != nil {
-		user.DisplayName = *input.DisplayName
-	}
-	if input.Phone != nil {
-		user.Phone = *input.Phone
-	}
-	if input.Country != nil {
-		user.Country = *input.Country
-	}
-	if input.Bio != nil {
-		user.Bio = *input.Bio
-	}
-
-	if err := database.DB.Save(&user).Error; err != nil {
+	user, err := ac.store.Users().UpdateProfile(c.Request.Context(), user.ID, repository.ProfileUpdate{
+		DisplayName: input.DisplayName,
+		Phone:       input.Phone,
+		Country:     input.Country,
+		Bio:         input.Bio,
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user profile"})
 		return
 	}
@@ -106,15 +131,278 @@ func (ac *AuthController) UpdateUserProfile(c *gin.Context) {
 	})
 }
 
-// ListUsers returns a list of all users
+// ListUsers returns a page of users, newest first. Optional filters: email (substring),
+// country, role, createdAfter and createdBefore (RFC 3339 or YYYY-MM-DD), and suspended.
 func (ac *AdminController) ListUsers(c *gin.Context) {
-	var users []models.User
-	if err := database.DB.Find(&users).Error; err != nil {
+	page, pageSize, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	filter := repository.UserFilter{
+		Email:   c.Query("email"),
+		Country: c.Query("country"),
+	}
+	if name := c.Query("role"); name != "" {
+		role, err := rbac.Parse(name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Role = role
+	}
+	for param, field := range map[string]**time.Time{"createdAfter": &filter.CreatedAfter, "createdBefore": &filter.CreatedBefore} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time or YYYY-MM-DD date"})
+			return
+		}
+		*field = &t
+	}
+	switch c.Query("suspended") {
+	case "":
+	case "true", "false":
+		suspended := c.Query("suspended") == "true"
+		filter.Suspended = &suspended
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "suspended must be true or false"})
+		return
+	}
+
+	users, total, err := ac.store.Users().List(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	// Load the page's roles in one query
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	roles, err := ac.store.Users().RolesByUser(c.Request.Context(), ids)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
+	views := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		views = append(views, adminUserView(user, roles[user.ID]))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"users": users,
+		"users":    views,
+		"page":     page,
+		"pageSize": pageSize,
+		"total":    total,
 	})
 }
+
+// DemoteUser removes all of a user's roles, leaving them a plain player
+func (ac *AdminController) DemoteUser(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := loadTargetUser(c, ac.store.Users())
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var revoked []rbac.Role
+	err := ac.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		if revoked, err = tx.Users().RevokeAllRoles(ctx, actor.ID, target.ID); err != nil {
+			return err
+		}
+		after, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), actor, auditUserDemote, "user", target.ID, before, after)
+	})
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	if err := applyRoleRemoval(ctx, ac.provider, ac.store.Users(), target); err != nil {
+		slog.ErrorContext(ctx, "Failed to apply demotion to the identity provider", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": roleRemovalFailed})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User demoted",
+		"userId":  target.ID,
+		"revoked": revoked,
+	})
+}
+
+// SuspendUser blocks a user from the API and disables their identity provider account,
+// signing them out of every session
+func (ac *AdminController) SuspendUser(c *gin.Context) {
+	var input models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := loadTargetUser(c, ac.store.Users())
+	if !ok {
+		return
+	}
+	if target.ID == actor.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend yourself"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	roles, err := ac.store.Users().Roles(ctx, target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles"})
+		return
+	}
+	if rbac.Has(roles, rbac.PermissionManageRoles) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Demote this user before suspending them"})
+		return
+	}
+
+	now := time.Now()
+	err = ac.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Users().Suspend(ctx, target.ID, input.Reason, now); err != nil {
+			return err
+		}
+		after, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), actor, auditUserSuspend, "user", target.ID, before, after)
+	})
+	alreadySuspended := errors.Is(err, repository.ErrAlreadySuspended)
+	if err != nil && !alreadySuspended {
+		slog.ErrorContext(ctx, "Failed to suspend user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	// The committed suspension already blocks the API. Disabling the account also ends
+	// sign-ins and token refreshes; it runs again when the request is retried, so a
+	// provider failure after an earlier commit can be repaired.
+	err = ac.provider.SetDisabled(ctx, target.FirebaseUID, true)
+	if err == nil {
+		err = ac.provider.RevokeRefreshTokens(ctx, target.FirebaseUID)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to disable suspended user's account", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User suspended, but their account could not be disabled; retry the request"})
+		return
+	}
+	if alreadySuspended {
+		c.JSON(http.StatusConflict, gin.H{"error": repository.ErrAlreadySuspended.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "User suspended",
+		"userId":      target.ID,
+		"suspendedAt": now,
+	})
+}
+
+// UnsuspendUser lifts a suspension and re-enables the identity provider account
+func (ac *AdminController) UnsuspendUser(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := loadTargetUser(c, ac.store.Users())
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := ac.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Users().Unsuspend(ctx, target.ID); err != nil {
+			return err
+		}
+		after, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), actor, auditUserUnsuspend, "user", target.ID, before, after)
+	})
+	notSuspended := errors.Is(err, repository.ErrNotSuspended)
+	if err != nil && !notSuspended {
+		slog.ErrorContext(ctx, "Failed to unsuspend user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsuspend user"})
+		return
+	}
+
+	// Re-enable the account after the commit; a retry repeats this step
+	if err := ac.provider.SetDisabled(ctx, target.FirebaseUID, false); err != nil {
+		slog.ErrorContext(ctx, "Failed to re-enable unsuspended user's account", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User unsuspended, but their account could not be re-enabled; retry the request"})
+		return
+	}
+	if notSuspended {
+		c.JSON(http.StatusConflict, gin.H{"error": repository.ErrNotSuspended.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unsuspended",
+		"userId":  target.ID,
+	})
+}
+
+// userSnapshot captures a user and their roles for the audit log
+func userSnapshot(c *gin.Context, users repository.UserRepository, userID uint) (gin.H, error) {
+	user, err := users.FindByID(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := users.Roles(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	return adminUserView(user, roles), nil
+}
+
+func adminUserView(user models.User, roles []rbac.Role) gin.H {
+	return gin.H{
+		"id":               user.ID,
+		"uid":              user.FirebaseUID,
+		"email":            user.Email,
+		"emailVerified":    user.EmailVerified,
+		"displayName":      user.DisplayName,
+		"country":          user.Country,
+		"isAdmin":          user.IsAdmin,
+		"roles":            roles,
+		"rating":           user.Rating,
+		"suspendedAt":      user.SuspendedAt,
+		"suspensionReason": user.SuspensionReason,
+		"createdAt":        user.CreatedAt,
+	}
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/logging"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Audited actions
+const (
+	auditUserPromote    = "user.promote"
+	auditUserDemote     = "user.demote"
+	auditUserSuspend    = "user.suspend"
+	auditUserUnsuspend  = "user.unsuspend"
+	auditRoleGrant      = "role.grant"
+	auditRoleRevoke     = "role.revoke"
+	auditQuestionCreate = "question.create"
+	auditContestCreate  = "contest.create"
+	auditContestUpdate  = "contest.update"
+	auditContestDelete  = "contest.delete"
+)
+
+// recordAudit appends an entry to the admin audit log. Pass the audit repository of the
+// transaction making the change so the entry is committed or rolled back with it. before
+// and after are encoded as JSON; pass nil for a side that does not exist.
+func recordAudit(c *gin.Context, audit repository.AuditRepository, actor models.User, action, targetType string, targetID interface{}, before, after interface{}) error {
+	entry := models.AuditLog{
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+		IPAddress:  c.ClientIP(),
+		RequestID:  logging.RequestID(c.Request.Context()),
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+	return audit.Append(c.Request.Context(), &entry)
+}
+
+func auditSnapshot(value interface{}) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	snapshot := string(data)
+	return &snapshot, nil
+}
+
+type AuditController struct{}
+
+// ListAuditLog returns audit entries, newest first. Optional filters: actorId, action,
+// targetType, targetId, since and until (RFC 3339 or YYYY-MM-DD).
+func (ac *AuditController) ListAuditLog(c *gin.Context) {
+	page, pageSize, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.AuditLog{})
+	if actorID := c.Query("actorId"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actorId"})
+			return
+		}
+		query = query.Where("actor_id = ?", id)
+	}
+	for param, column := range map[string]string{"action": "action", "targetType": "target_type", "targetId": "target_id"} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+	for param, condition := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time or YYYY-MM-DD date"})
+			return
+		}
+		query = query.Where(condition, t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	views := make([]gin.H, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, gin.H{
+			"id":         entry.ID,
+			"actorId":    entry.ActorID,
+			"action":     entry.Action,
+			"targetType": entry.TargetType,
+			"targetId":   entry.TargetID,
+			"before":     rawJSON(entry.Before),
+			"after":      rawJSON(entry.After),
+			"ipAddress":  entry.IPAddress,
+			"requestId":  entry.RequestID,
+			"createdAt":  entry.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":  views,
+		"page":     page,
+		"pageSize": pageSize,
+		"total":    total,
+	})
+}
+
+func rawJSON(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return json.RawMessage(*value)
+}
+
+// parseDateParam accepts an RFC 3339 timestamp or a plain YYYY-MM-DD date (midnight UTC)
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,740 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ContestController struct {
+	store repository.Store
+}
+
+// NewContestController creates a new instance of ContestController
+func NewContestController(store repository.Store) *ContestController {
+	return &ContestController{store: store}
+}
+
+// CreateContest creates a timed contest from a fixed set of existing questions
+func (cc *ContestController) CreateContest(c *gin.Context) {
+	var input models.CreateContestRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateContestWindow(input.StartTime, input.EndTime, input.FreezeMinutes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the admin user who is creating the contest
+	admin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	contestQuestions, err := resolveContestQuestions(input.QuestionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contest := models.Contest{
+		Title:                input.Title,
+		Description:          input.Description,
+		StartTime:            input.StartTime,
+		EndTime:              input.EndTime,
+		FreezeMinutes:        input.FreezeMinutes,
+		TimePenaltyPerMinute: input.TimePenaltyPerMinute,
+		WrongAttemptPenalty:  input.WrongAttemptPenalty,
+		CreatedBy:            admin.ID,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&contest).Error; err != nil {
+			return err
+		}
+		for i := range contestQuestions {
+			contestQuestions[i].ContestID = contest.ID
+		}
+		if err := tx.Omit("Question").Create(&contestQuestions).Error; err != nil {
+			return err
+		}
+		contest.Questions = contestQuestions
+		return recordAudit(c, repository.NewPostgresAudit(tx), admin, auditContestCreate, "contest", contest.ID, nil, contestView(contest, time.Now(), true, true))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save contest to database"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Contest created successfully",
+		"contest": contestView(contest, time.Now(), true, true),
+	})
+}
+
+// ListContests returns all contests, most recent first
+func (cc *ContestController) ListContests(c *gin.Context) {
+	var contests []models.Contest
+	if err := database.DB.Order("start_time DESC").Find(&contests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve contests"})
+		return
+	}
+
+	now := time.Now()
+	views := make([]gin.H, len(contests))
+	for i, contest := range contests {
+		views[i] = contestView(contest, now, false, false)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(views),
+		"contests": views,
+	})
+}
+
+// GetContest returns a contest for players. Questions are only revealed once
+// the contest has started, and answers are never included.
+func (cc *ContestController) GetContest(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	view := contestView(contest, now, contest.Status(now) != models.ContestUpcoming, false)
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.ContestRegistration{}).
+		Where("contest_id = ? AND user_id = ?", contest.ID, user.ID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve registration"})
+		return
+	}
+	view["registered"] = count > 0
+
+	c.JSON(http.StatusOK, view)
+}
+
+// GetContestAdmin returns a contest with its questions and answers
+func (cc *ContestController) GetContestAdmin(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, contestView(contest, time.Now(), true, true))
+}
+
+// UpdateContest updates a contest's schedule, scoring rules or questions
+func (cc *ContestController) UpdateContest(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+	admin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	before := contestView(contest, time.Now(), true, true)
+
+	var input models.UpdateContestRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Stored submission points depend on the schedule and penalties, so they are fixed once the contest starts
+	if err := checkStartedContestUpdate(contest, input, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update only provided fields
+	if input.Title != nil {
+		contest.Title = *input.Title
+	}
+	if input.Description != nil {
+		contest.Description = *input.Description
+	}
+	if input.StartTime != nil {
+		contest.StartTime = *input.StartTime
+	}
+	if input.EndTime != nil {
+		contest.EndTime = *input.EndTime
+	}
+	if input.FreezeMinutes != nil {
+		contest.FreezeMinutes = *input.FreezeMinutes
+	}
+	if input.TimePenaltyPerMinute != nil {
+		contest.TimePenaltyPerMinute = *input.TimePenaltyPerMinute
+	}
+	if input.WrongAttemptPenalty != nil {
+		contest.WrongAttemptPenalty = *input.WrongAttemptPenalty
+	}
+
+	if err := validateContestWindow(contest.StartTime, contest.EndTime, contest.FreezeMinutes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var newQuestions []models.ContestQuestion
+	if input.QuestionIDs != nil {
+		if contest.Status(time.Now()) != models.ContestUpcoming {
+			c.JSON(http.StatusConflict, gin.H{"error": "Questions cannot be changed after the contest has started"})
+			return
+		}
+
+		var err error
+		newQuestions, err = resolveContestQuestions(input.QuestionIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&contest).Error; err != nil {
+			return err
+		}
+		if newQuestions != nil {
+			if err := tx.Where("contest_id = ?", contest.ID).Delete(&models.ContestQuestion{}).Error; err != nil {
+				return err
+			}
+			for i := range newQuestions {
+				newQuestions[i].ContestID = contest.ID
+			}
+			if err := tx.Omit("Question").Create(&newQuestions).Error; err != nil {
+				return err
+			}
+			contest.Questions = newQuestions
+		}
+		return recordAudit(c, repository.NewPostgresAudit(tx), admin, auditContestUpdate, "contest", contest.ID, before, contestView(contest, time.Now(), true, true))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update contest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contest updated successfully",
+		"contest": contestView(contest, time.Now(), true, true),
+	})
+}
+
+// DeleteContest removes a contest
+func (cc *ContestController) DeleteContest(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+	admin, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&contest).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, repository.NewPostgresAudit(tx), admin, auditContestDelete, "contest", contest.ID, contestView(contest, time.Now(), true, true), nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete contest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contest deleted successfully"})
+}
+
+// RegisterForContest registers the caller for a contest that has not yet ended
+func (cc *ContestController) RegisterForContest(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !requireVerified(c, user, verifiedForRanked) {
+		return
+	}
+
+	if contest.Status(time.Now()) == models.ContestEnded {
+		c.JSON(http.StatusConflict, gin.H{"error": "Contest has already ended"})
+		return
+	}
+
+	registration := models.ContestRegistration{
+		ContestID: contest.ID,
+		UserID:    user.ID,
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("User").Create(&registration)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register for contest"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already registered for this contest"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Registered for contest successfully",
+		"contestId": contest.ID,
+	})
+}
+
+// SubmitContestAnswer checks an answer to a contest question and scores it
+func (cc *ContestController) SubmitContestAnswer(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	var input models.SubmitContestAnswerRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if contest.Status(now) != models.ContestRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Contest is not running"})
+		return
+	}
+
+	var contestQuestion *models.ContestQuestion
+	for i := range contest.Questions {
+		if contest.Questions[i].Question.QuestionID == input.QuestionID {
+			contestQuestion = &contest.Questions[i]
+			break
+		}
+	}
+	if contestQuestion == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question is not part of this contest"})
+		return
+	}
+
+	var submission models.Submission
+	var wrongAttempts int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the registration row so concurrent submissions from the same user are serialized
+		var registration models.ContestRegistration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("contest_id = ? AND user_id = ?", contest.ID, user.ID).
+			First(&registration).Error; err != nil {
+			return errNotRegistered
+		}
+
+		var solved int64
+		if err := tx.Model(&models.Submission{}).
+			Where("contest_id = ? AND user_id = ? AND question_id = ? AND is_correct", contest.ID, user.ID, contestQuestion.QuestionID).
+			Count(&solved).Error; err != nil {
+			return err
+		}
+		if solved > 0 {
+			return errAlreadySolved
+		}
+
+		if err := tx.Model(&models.Submission{}).
+			Where("contest_id = ? AND user_id = ? AND question_id = ? AND NOT is_correct", contest.ID, user.ID, contestQuestion.QuestionID).
+			Count(&wrongAttempts).Error; err != nil {
+			return err
+		}
+
+		submission = models.Submission{
+			UserID:     user.ID,
+			QuestionID: contestQuestion.QuestionID,
+			ContestID:  &contest.ID,
+			Answer:     input.Answer,
+			IsCorrect:  answersMatch(input.Answer, contestQuestion.Question.Answer),
+		}
+		if submission.IsCorrect {
+			submission.Points = contestPoints(contest, *contestQuestion, now, int(wrongAttempts))
+		}
+
+		return tx.Create(&submission).Error
+	})
+	switch {
+	case errors.Is(err, errNotRegistered):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not registered for this contest"})
+		return
+	case errors.Is(err, errAlreadySolved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Question already solved"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save submission"})
+		return
+	}
+	countSubmission("contest", submission.IsCorrect)
+
+	attempts := wrongAttempts
+	if !submission.IsCorrect {
+		attempts++
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"correct":              submission.IsCorrect,
+		"points":               submission.Points,
+		"wrongAttempts":        attempts,
+		"submittedAt":          submission.CreatedAt,
+		"achievementsUnlocked": unlockAchievements(c.Request.Context(), cc.store.Achievements(), user.ID, achievements.EventSubmission),
+	})
+}
+
+// GetScoreboard returns the public scoreboard, which stops updating during the freeze period
+func (cc *ContestController) GetScoreboard(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	frozen := contest.IsFrozen(now)
+
+	cutoff := now
+	if frozen {
+		cutoff = contest.FreezeTime()
+	}
+
+	rows, err := buildScoreboard(contest, cutoff)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build scoreboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contestId":  contest.ID,
+		"status":     contest.Status(now),
+		"frozen":     frozen,
+		"frozenAt":   freezeTimeOrNil(contest, frozen),
+		"scoreboard": rows,
+	})
+}
+
+// GetLiveScoreboard returns the scoreboard ignoring the freeze period
+func (cc *ContestController) GetLiveScoreboard(c *gin.Context) {
+	contest, ok := loadContest(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	rows, err := buildScoreboard(contest, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build scoreboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contestId":  contest.ID,
+		"status":     contest.Status(now),
+		"frozen":     false,
+		"scoreboard": rows,
+	})
+}
+
+var (
+	errNotRegistered = errors.New("not registered")
+	errAlreadySolved = errors.New("already solved")
+)
+
+// loadContest fetches the contest named by the :id path parameter with its questions
+func loadContest(c *gin.Context) (models.Contest, bool) {
+	var contest models.Contest
+
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return contest, false
+	}
+
+	err := database.DB.
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Questions.Question").
+		First(&contest, id).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contest not found"})
+		return contest, false
+	}
+
+	return contest, true
+}
+
+// validateContestWindow checks that the contest schedule is consistent
+func validateContestWindow(start, end time.Time, freezeMinutes int) error {
+	if !end.After(start) {
+		return fmt.Errorf("endTime must be after startTime")
+	}
+	if time.Duration(freezeMinutes)*time.Minute > end.Sub(start) {
+		return fmt.Errorf("freezeMinutes cannot exceed the contest duration")
+	}
+	return nil
+}
+
+// checkStartedContestUpdate rejects changes to a contest that has started which would make
+// the points already stored on submissions inconsistent. The only schedule change allowed
+// is moving EndTime later while the contest is still running and the scoreboard has not
+// frozen; extending a frozen contest would move the freeze and unhide solves.
+func checkStartedContestUpdate(contest models.Contest, input models.UpdateContestRequest, now time.Time) error {
+	if contest.Status(now) == models.ContestUpcoming {
+		return nil
+	}
+	if input.StartTime != nil && !input.StartTime.Equal(contest.StartTime) {
+		return fmt.Errorf("startTime cannot be changed after the contest has started")
+	}
+	if (input.TimePenaltyPerMinute != nil && *input.TimePenaltyPerMinute != contest.TimePenaltyPerMinute) ||
+		(input.WrongAttemptPenalty != nil && *input.WrongAttemptPenalty != contest.WrongAttemptPenalty) {
+		return fmt.Errorf("penalties cannot be changed after the contest has started")
+	}
+	if input.FreezeMinutes != nil && *input.FreezeMinutes != contest.FreezeMinutes {
+		return fmt.Errorf("freezeMinutes cannot be changed after the contest has started")
+	}
+	if input.EndTime != nil && !input.EndTime.Equal(contest.EndTime) {
+		if contest.Status(now) == models.ContestEnded {
+			return fmt.Errorf("endTime cannot be changed after the contest has ended")
+		}
+		if contest.IsFrozen(now) {
+			return fmt.Errorf("endTime cannot be changed after the scoreboard has frozen")
+		}
+		if input.EndTime.Before(contest.EndTime) {
+			return fmt.Errorf("endTime can only be extended after the contest has started")
+		}
+	}
+	return nil
+}
+
+// resolveContestQuestions looks up questions by their public question_id, keeping the given order
+func resolveContestQuestions(questionIDs []string) ([]models.ContestQuestion, error) {
+	var questions []models.Question
+	if err := database.DB.Where("question_id IN ?", questionIDs).Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve questions")
+	}
+
+	byID := make(map[string]models.Question, len(questions))
+	for _, q := range questions {
+		byID[q.QuestionID] = q
+	}
+
+	seen := make(map[string]bool, len(questionIDs))
+	contestQuestions := make([]models.ContestQuestion, 0, len(questionIDs))
+	for _, id := range questionIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("question %s is listed more than once", id)
+		}
+		seen[id] = true
+
+		q, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("question %s not found", id)
+		}
+		contestQuestions = append(contestQuestions, models.ContestQuestion{
+			QuestionID: q.ID,
+			Position:   len(contestQuestions) + 1,
+			Points:     q.Points,
+			Question:   q,
+		})
+	}
+
+	return contestQuestions, nil
+}
+
+// contestPoints applies the contest's time and wrong-attempt penalties to a correct answer
+func contestPoints(contest models.Contest, question models.ContestQuestion, solvedAt time.Time, wrongAttempts int) int {
+	elapsedMinutes := int(solvedAt.Sub(contest.StartTime).Minutes())
+	points := question.Points -
+		elapsedMinutes*contest.TimePenaltyPerMinute -
+		wrongAttempts*contest.WrongAttemptPenalty
+	if points < 0 {
+		return 0
+	}
+	return points
+}
+
+// buildScoreboard ranks registered users using submissions made before cutoff
+func buildScoreboard(contest models.Contest, cutoff time.Time) ([]gin.H, error) {
+	var registrations []models.ContestRegistration
+	if err := database.DB.Preload("User").Where("contest_id = ?", contest.ID).Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	var submissions []models.Submission
+	if err := database.DB.
+		Where("contest_id = ? AND created_at < ?", contest.ID, cutoff).
+		Order("created_at").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	type cell struct {
+		solved        bool
+		wrongAttempts int
+		points        int
+		solvedAt      time.Time
+	}
+	type entry struct {
+		user      models.User
+		score     int
+		solved    int
+		wrong     int
+		lastSolve time.Time
+		cells     map[uint]*cell
+	}
+
+	entries := make(map[uint]*entry, len(registrations))
+	for _, r := range registrations {
+		entries[r.UserID] = &entry{user: r.User, cells: make(map[uint]*cell)}
+	}
+
+	for _, s := range submissions {
+		e, ok := entries[s.UserID]
+		if !ok {
+			continue
+		}
+		cl, ok := e.cells[s.QuestionID]
+		if !ok {
+			cl = &cell{}
+			e.cells[s.QuestionID] = cl
+		}
+		if cl.solved {
+			continue
+		}
+		if s.IsCorrect {
+			cl.solved = true
+			cl.points = s.Points
+			cl.solvedAt = s.CreatedAt
+			e.score += s.Points
+			e.solved++
+			if s.CreatedAt.After(e.lastSolve) {
+				e.lastSolve = s.CreatedAt
+			}
+		} else {
+			cl.wrongAttempts++
+			e.wrong++
+		}
+	}
+
+	ranked := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		ranked = append(ranked, e)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.solved != b.solved {
+			return a.solved > b.solved
+		}
+		if !a.lastSolve.Equal(b.lastSolve) {
+			return a.lastSolve.Before(b.lastSolve)
+		}
+		return a.user.ID < b.user.ID
+	})
+
+	rows := make([]gin.H, len(ranked))
+	for i, e := range ranked {
+		questions := make([]gin.H, len(contest.Questions))
+		for j, q := range contest.Questions {
+			view := gin.H{
+				"questionId":    q.Question.QuestionID,
+				"solved":        false,
+				"wrongAttempts": 0,
+				"points":        0,
+			}
+			if cl, ok := e.cells[q.QuestionID]; ok {
+				view["solved"] = cl.solved
+				view["wrongAttempts"] = cl.wrongAttempts
+				view["points"] = cl.points
+				if cl.solved {
+					view["solvedAt"] = cl.solvedAt
+				}
+			}
+			questions[j] = view
+		}
+
+		rows[i] = gin.H{
+			"rank":          i + 1,
+			"userId":        e.user.ID,
+			"displayName":   e.user.DisplayName,
+			"score":         e.score,
+			"solved":        e.solved,
+			"wrongAttempts": e.wrong,
+			"questions":     questions,
+		}
+	}
+
+	return rows, nil
+}
+
+func freezeTimeOrNil(contest models.Contest, frozen bool) interface{} {
+	if !frozen {
+		return nil
+	}
+	return contest.FreezeTime()
+}
+
+// contestView builds the JSON representation of a contest
+func contestView(contest models.Contest, now time.Time, withQuestions, withAnswers bool) gin.H {
+	view := gin.H{
+		"id":                   contest.ID,
+		"title":                contest.Title,
+		"description":          contest.Description,
+		"startTime":            contest.StartTime,
+		"endTime":              contest.EndTime,
+		"freezeMinutes":        contest.FreezeMinutes,
+		"timePenaltyPerMinute": contest.TimePenaltyPerMinute,
+		"wrongAttemptPenalty":  contest.WrongAttemptPenalty,
+		"status":               contest.Status(now),
+	}
+
+	if withQuestions {
+		questions := make([]gin.H, len(contest.Questions))
+		for i, cq := range contest.Questions {
+			q := gin.H{
+				"questionId":   cq.Question.QuestionID,
+				"position":     cq.Position,
+				"points":       cq.Points,
+				"title":        cq.Question.Title,
+				"question":     cq.Question.Question,
+				"difficulty":   cq.Question.Difficulty,
+				"category":     cq.Question.Category,
+				"expectedTime": cq.Question.ExpectedTime,
+				"imageUrl":     cq.Question.ImageUrl,
+			}
+			if withAnswers {
+				q["answer"] = cq.Question.Answer
+				q["explanation"] = cq.Question.Explanation
+			}
+			questions[i] = q
+		}
+		view["questions"] = questions
+	}
+
+	return view
+}
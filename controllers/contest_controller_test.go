@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+)
+
+func TestContestPoints(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	contest := models.Contest{StartTime: start, EndTime: start.Add(2 * time.Hour), TimePenaltyPerMinute: 2, WrongAttemptPenalty: 10}
+	question := models.ContestQuestion{Points: 100}
+
+	tests := []struct {
+		name          string
+		solvedAt      time.Time
+		wrongAttempts int
+		want          int
+	}{
+		{"at start", start, 0, 100},
+		{"partial minutes are not charged", start.Add(59 * time.Second), 0, 100},
+		{"time penalty", start.Add(10 * time.Minute), 0, 80},
+		{"wrong attempts", start.Add(10 * time.Minute), 3, 50},
+		{"never negative", start.Add(time.Hour), 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contestPoints(contest, question, tt.solvedAt, tt.wrongAttempts); got != tt.want {
+				t.Errorf("contestPoints = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStartedContestUpdate(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	contest := models.Contest{StartTime: start, EndTime: start.Add(2 * time.Hour), FreezeMinutes: 30, TimePenaltyPerMinute: 2, WrongAttemptPenalty: 10}
+	timeOf := func(d time.Duration) *time.Time { v := start.Add(d); return &v }
+	intOf := func(v int) *int { return &v }
+
+	before := start.Add(-time.Minute)
+	running := start.Add(time.Hour)
+	frozen := start.Add(100 * time.Minute)
+	ended := start.Add(3 * time.Hour)
+
+	tests := []struct {
+		name    string
+		input   models.UpdateContestRequest
+		now     time.Time
+		wantErr bool
+	}{
+		{"anything before start", models.UpdateContestRequest{StartTime: timeOf(time.Hour), TimePenaltyPerMinute: intOf(5)}, before, false},
+		{"start time while running", models.UpdateContestRequest{StartTime: timeOf(time.Minute)}, running, true},
+		{"unchanged start time while running", models.UpdateContestRequest{StartTime: timeOf(0)}, running, false},
+		{"time penalty while running", models.UpdateContestRequest{TimePenaltyPerMinute: intOf(1)}, running, true},
+		{"wrong attempt penalty while running", models.UpdateContestRequest{WrongAttemptPenalty: intOf(0)}, running, true},
+		{"freeze while running", models.UpdateContestRequest{FreezeMinutes: intOf(0)}, running, true},
+		{"extend end while running", models.UpdateContestRequest{EndTime: timeOf(3 * time.Hour)}, running, false},
+		{"extend end while frozen", models.UpdateContestRequest{EndTime: timeOf(3 * time.Hour)}, frozen, true},
+		{"unchanged end while frozen", models.UpdateContestRequest{EndTime: timeOf(2 * time.Hour)}, frozen, false},
+		{"shorten end while running", models.UpdateContestRequest{EndTime: timeOf(90 * time.Minute)}, running, true},
+		{"extend end after it ended", models.UpdateContestRequest{EndTime: timeOf(4 * time.Hour)}, ended, true},
+		{"title after it ended", models.UpdateContestRequest{Title: new(string)}, ended, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStartedContestUpdate(contest, tt.input, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkStartedContestUpdate error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
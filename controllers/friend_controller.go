@@ -0,0 +1,559 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FriendController struct{}
+
+// ListFriends returns the caller's accepted friends
+func (fc *FriendController) ListFriends(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var friendships []models.Friendship
+	if err := database.DB.Preload("Requester").Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", user.ID, user.ID, models.FriendshipAccepted).
+		Order("accepted_at DESC").
+		Find(&friendships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friends"})
+		return
+	}
+
+	friends := make([]gin.H, len(friendships))
+	for i, f := range friendships {
+		view := userSummary(f.OtherUser(user.ID))
+		view["friendsSince"] = f.AcceptedAt
+		friends[i] = view
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(friends),
+		"friends": friends,
+	})
+}
+
+// ListFriendRequests returns the caller's pending incoming and outgoing friend requests
+func (fc *FriendController) ListFriendRequests(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var pending []models.Friendship
+	if err := database.DB.Preload("Requester").Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", user.ID, user.ID, models.FriendshipPending).
+		Order("created_at DESC").
+		Find(&pending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friend requests"})
+		return
+	}
+
+	incoming := []gin.H{}
+	outgoing := []gin.H{}
+	for _, f := range pending {
+		view := gin.H{
+			"id":        f.ID,
+			"user":      userSummary(f.OtherUser(user.ID)),
+			"createdAt": f.CreatedAt,
+		}
+		if f.AddresseeID == user.ID {
+			incoming = append(incoming, view)
+		} else {
+			outgoing = append(outgoing, view)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incoming": incoming,
+		"outgoing": outgoing,
+	})
+}
+
+// SendFriendRequest sends a friend request. If the other user already sent one to the caller,
+// that request is accepted instead.
+func (fc *FriendController) SendFriendRequest(c *gin.Context) {
+	var input models.TargetUserRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if input.UserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot send a friend request to yourself"})
+		return
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var friendship models.Friendship
+	accepted := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		blocked, err := isBlocked(tx, user.ID, target.ID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return errBlocked
+		}
+
+		existing, err := friendshipBetween(tx, user.ID, target.ID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			friendship = models.Friendship{
+				RequesterID: user.ID,
+				AddresseeID: target.ID,
+				Status:      models.FriendshipPending,
+			}
+			return tx.Omit(clause.Associations).Create(&friendship).Error
+		case err != nil:
+			return err
+		case existing.Status == models.FriendshipAccepted:
+			return errAlreadyFriends
+		case existing.RequesterID == user.ID:
+			return errRequestAlreadySent
+		}
+
+		// The target already asked the caller, so sending back means accepting
+		now := time.Now()
+		existing.Status = models.FriendshipAccepted
+		existing.AcceptedAt = &now
+		friendship = existing
+		accepted = true
+		return tx.Omit(clause.Associations).Save(&friendship).Error
+	})
+	if err != nil {
+		respondFriendError(c, err, "Failed to send friend request")
+		return
+	}
+
+	if accepted {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Friend request accepted",
+			"id":      friendship.ID,
+			"friend":  userSummary(target),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Friend request sent",
+		"id":      friendship.ID,
+		"user":    userSummary(target),
+	})
+}
+
+// AcceptFriendRequest accepts a pending friend request addressed to the caller
+func (fc *FriendController) AcceptFriendRequest(c *gin.Context) {
+	friendship, user, ok := loadPendingRequest(c)
+	if !ok {
+		return
+	}
+
+	if friendship.AddresseeID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the recipient can accept a friend request"})
+		return
+	}
+
+	now := time.Now()
+	friendship.Status = models.FriendshipAccepted
+	friendship.AcceptedAt = &now
+	if err := database.DB.Omit(clause.Associations).Save(&friendship).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept friend request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Friend request accepted",
+		"friend":  userSummary(friendship.Requester),
+	})
+}
+
+// DeclineFriendRequest declines a pending request addressed to the caller,
+// or cancels one the caller sent
+func (fc *FriendController) DeclineFriendRequest(c *gin.Context) {
+	friendship, _, ok := loadPendingRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(&friendship).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline friend request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request declined"})
+}
+
+// RemoveFriend ends a friendship with the user named by the :id path parameter
+func (fc *FriendController) RemoveFriend(c *gin.Context) {
+	friendID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.
+		Where("((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)) AND status = ?",
+			user.ID, friendID, friendID, user.ID, models.FriendshipAccepted).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove friend"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed"})
+}
+
+// FriendLeaderboard ranks the caller and their friends by rating
+func (fc *FriendController) FriendLeaderboard(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	ids, err := friendIDs(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friends"})
+		return
+	}
+	ids = append(ids, user.ID)
+
+	var users []models.User
+	if err := database.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build leaderboard"})
+		return
+	}
+
+	var solvedCounts []struct {
+		UserID uint
+		Solved int
+	}
+	if err := database.DB.Model(&models.Submission{}).
+		Select("user_id, COUNT(DISTINCT question_id) AS solved").
+		Where("user_id IN ? AND is_correct", ids).
+		Group("user_id").
+		Scan(&solvedCounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build leaderboard"})
+		return
+	}
+	solved := make(map[uint]int, len(solvedCounts))
+	for _, s := range solvedCounts {
+		solved[s.UserID] = s.Solved
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Rating != users[j].Rating {
+			return users[i].Rating > users[j].Rating
+		}
+		if solved[users[i].ID] != solved[users[j].ID] {
+			return solved[users[i].ID] > solved[users[j].ID]
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	leaderboard := make([]gin.H, len(users))
+	for i, u := range users {
+		row := userSummary(u)
+		row["rank"] = i + 1
+		row["solved"] = solved[u.ID]
+		row["isYou"] = u.ID == user.ID
+		leaderboard[i] = row
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":       len(leaderboard),
+		"leaderboard": leaderboard,
+	})
+}
+
+// ChallengeFriend creates a one-on-one room reserved for the friend named by the :id path parameter
+func (fc *FriendController) ChallengeFriend(c *gin.Context) {
+	friendID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ChallengeFriendRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if input.Ranked && !requireVerified(c, user, verifiedForRanked) {
+		return
+	}
+
+	friendship, err := friendshipBetween(database.DB, user.ID, friendID)
+	if err != nil || friendship.Status != models.FriendshipAccepted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only challenge friends"})
+		return
+	}
+
+	room, err := createRoom(user, models.CreateRoomRequest{
+		Category:           input.Category,
+		Difficulty:         input.Difficulty,
+		QuestionCount:      input.QuestionCount,
+		SecondsPerQuestion: input.SecondsPerQuestion,
+		MaxParticipants:    2,
+		Ranked:             input.Ranked,
+	}, &friendID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create challenge"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Challenge sent",
+		"room":    roomView(room, time.Now()),
+	})
+}
+
+// ListChallenges returns open challenges the caller has received from friends
+func (fc *FriendController) ListChallenges(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var rooms []models.Room
+	if err := database.DB.Preload("Participants.User").
+		Where("challenged_id = ? AND status = ?", user.ID, models.RoomWaiting).
+		Order("created_at DESC").
+		Find(&rooms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve challenges"})
+		return
+	}
+
+	now := time.Now()
+	challenges := make([]gin.H, len(rooms))
+	for i, room := range rooms {
+		challenges[i] = roomView(room, now)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(challenges),
+		"challenges": challenges,
+	})
+}
+
+// ListBlockedUsers returns the users the caller has blocked
+func (fc *FriendController) ListBlockedUsers(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var blocks []models.UserBlock
+	if err := database.DB.Preload("Blocked").Where("blocker_id = ?", user.ID).Order("created_at DESC").Find(&blocks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blocked users"})
+		return
+	}
+
+	blocked := make([]gin.H, len(blocks))
+	for i, b := range blocks {
+		view := userSummary(b.Blocked)
+		view["blockedAt"] = b.CreatedAt
+		blocked[i] = view
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(blocked),
+		"blocked": blocked,
+	})
+}
+
+// BlockUser blocks another user, removing any friendship or pending request between them
+func (fc *FriendController) BlockUser(c *gin.Context) {
+	var input models.TargetUserRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if input.UserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot block yourself"})
+		return
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		block := models.UserBlock{BlockerID: user.ID, BlockedID: target.ID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Blocked").Create(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
+			user.ID, target.ID, target.ID, user.ID).
+			Delete(&models.Friendship{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+// UnblockUser removes a block on the user named by the :id path parameter
+func (fc *FriendController) UnblockUser(c *gin.Context) {
+	blockedID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("blocker_id = ? AND blocked_id = ?", user.ID, blockedID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not blocked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
+
+var (
+	errBlocked            = errors.New("blocked")
+	errAlreadyFriends     = errors.New("already friends")
+	errRequestAlreadySent = errors.New("friend request already sent")
+)
+
+// respondFriendError maps friendship errors to HTTP responses
+func respondFriendError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errBlocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot send a friend request to this user"})
+	case errors.Is(err, errAlreadyFriends):
+		c.JSON(http.StatusConflict, gin.H{"error": "Already friends"})
+	case errors.Is(err, errRequestAlreadySent):
+		c.JSON(http.StatusConflict, gin.H{"error": "Friend request already sent"})
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		// The other user sent a request at the same moment
+		c.JSON(http.StatusConflict, gin.H{"error": "A friend request between these users already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// loadPendingRequest fetches the pending friend request named by the :id path parameter
+// and checks that the caller is one of its two users
+func loadPendingRequest(c *gin.Context) (models.Friendship, models.User, bool) {
+	var friendship models.Friendship
+
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return friendship, models.User{}, false
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return friendship, user, false
+	}
+
+	err := database.DB.Preload("Requester").Preload("Addressee").
+		Where("id = ? AND status = ? AND (requester_id = ? OR addressee_id = ?)", id, models.FriendshipPending, user.ID, user.ID).
+		First(&friendship).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+		return friendship, user, false
+	}
+
+	return friendship, user, true
+}
+
+// friendshipBetween returns the friendship row between two users in either direction
+func friendshipBetween(db *gorm.DB, a, b uint) (models.Friendship, error) {
+	var friendship models.Friendship
+	err := db.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", a, b, b, a).
+		First(&friendship).Error
+	return friendship, err
+}
+
+// friendIDs returns the IDs of the user's accepted friends
+func friendIDs(db *gorm.DB, userID uint) ([]uint, error) {
+	var friendships []models.Friendship
+	if err := db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, models.FriendshipAccepted).
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(friendships))
+	for i, f := range friendships {
+		if f.RequesterID == userID {
+			ids[i] = f.AddresseeID
+		} else {
+			ids[i] = f.RequesterID
+		}
+	}
+	return ids, nil
+}
+
+// isBlocked reports whether either user has blocked the other
+func isBlocked(db *gorm.DB, a, b uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// userSummary builds the minimal public representation of a user
+func userSummary(user models.User) gin.H {
+	return gin.H{
+		"userId":      user.ID,
+		"displayName": user.DisplayName,
+		"photoURL":    user.PhotoURL,
+		"rating":      user.Rating,
+	}
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	checker *health.Checker
+}
+
+// NewHealthController creates a new instance of HealthController
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{checker: checker}
+}
+
+// Live reports that the process is up and serving. It checks no dependencies, so an
+// outage elsewhere does not get the container restarted.
+func (hc *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "OK"})
+}
+
+// Ready reports each dependency's status, answering 503 when a critical one is down
+// or the server is shutting down
+func (hc *HealthController) Ready(c *gin.Context) {
+	report := hc.checker.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser returns the database user AuthMiddleware loaded for the caller.
+// It writes an error response and returns false when there is none.
+func currentUser(c *gin.Context) (models.User, bool) {
+	if value, exists := c.Get("user"); exists {
+		if user, ok := value.(models.User); ok {
+			return user, true
+		}
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	return models.User{}, false
+}
+
+// uintParam parses a numeric path parameter, writing a 400 response on failure
+func uintParam(c *gin.Context, name string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return uint(value), true
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageParams parses the page and pageSize query parameters, writing a 400 response on failure
+func pageParams(c *gin.Context) (page, pageSize int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+		return 0, 0, false
+	}
+	pageSize, err = strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("pageSize must be between 1 and %d", maxPageSize)})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// answersMatch compares a submitted answer with the expected one,
+// ignoring case and surrounding/repeated whitespace
+func answersMatch(submitted, expected string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+	return normalize(submitted) == normalize(expected)
+}
+
+// countSubmission records a saved answer of the given kind ("contest" or "room") in the metrics
+func countSubmission(kind string, correct bool) {
+	metrics.Submissions.WithLabelValues(kind).Inc()
+	if correct {
+		metrics.CorrectAnswers.WithLabelValues(kind).Inc()
+	}
+}
+
+// unlockAchievements evaluates the achievement rules triggered by an event and returns
+// the caller's newly unlocked achievements for inclusion in the response
+func unlockAchievements(ctx context.Context, repo repository.AchievementRepository, userID uint, event achievements.Event) []map[string]interface{} {
+	if _, err := repo.Evaluate(ctx, userID, event); err != nil {
+		slog.ErrorContext(ctx, "Error evaluating achievements", "user_id", userID, "error", err)
+	}
+	return announceAchievements(ctx, repo, userID)
+}
+
+// announceAchievements returns achievements the user has earned but not yet been told about
+func announceAchievements(ctx context.Context, repo repository.AchievementRepository, userID uint) []map[string]interface{} {
+	pending, err := repo.TakeUnannounced(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading unlocked achievements", "user_id", userID, "error", err)
+		return []map[string]interface{}{}
+	}
+	return achievements.Views(pending)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oauthStateTTL      = 10 * time.Minute
+	oauthBindingCookie = "oauth_binding"
+	oauthCookiePath    = "/api/v1/auth/google"
+)
+
+var (
+	errOAuthStateInvalid = errors.New("invalid or expired state")
+	errOAuthStateBinding = errors.New("state was issued to a different client")
+)
+
+// saveOAuthState stores a new single-use state together with its PKCE verifier and
+// binds it to the client through an HttpOnly cookie holding a random nonce
+func saveOAuthState(c *gin.Context, states repository.OAuthStateRepository, state, verifier string) error {
+	binding, err := generateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	record := models.OAuthState{
+		StateHash:    hashToken(state),
+		BindingHash:  hashToken(binding),
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+	if err := states.Create(c.Request.Context(), &record); err != nil {
+		return err
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthBindingCookie, binding, int(oauthStateTTL.Seconds()), oauthCookiePath, "", secureCookies(), true)
+	return nil
+}
+
+// consumeOAuthState deletes the state and returns its PKCE verifier. It fails if the
+// state is unknown, expired, already used, or was issued to a different client.
+func consumeOAuthState(c *gin.Context, states repository.OAuthStateRepository, state string) (string, error) {
+	binding, _ := c.Cookie(oauthBindingCookie)
+	// The binding cookie is only good for one attempt
+	c.SetCookie(oauthBindingCookie, "", -1, oauthCookiePath, "", secureCookies(), true)
+
+	record, err := states.Consume(c.Request.Context(), hashToken(state))
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", errOAuthStateInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return "", errOAuthStateInvalid
+	}
+
+	if binding == "" || subtle.ConstantTimeCompare([]byte(hashToken(binding)), []byte(record.BindingHash)) != 1 {
+		return "", errOAuthStateBinding
+	}
+
+	return record.CodeVerifier, nil
+}
+
+// secureCookies reports whether cookies should carry the Secure attribute
+func secureCookies() bool {
+	return strings.HasPrefix(config.Current.PublicURL, "https://")
+}
+
+// generateRandomToken returns 32 random bytes encoded for use in URLs and cookies
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProfileController struct {
+	store repository.Store
+}
+
+// NewProfileController creates a new instance of ProfileController
+func NewProfileController(store repository.Store) *ProfileController {
+	return &ProfileController{store: store}
+}
+
+// GetPublicProfile returns another user's profile, showing only the fields
+// their privacy settings allow the caller to see
+func (pc *ProfileController) GetPublicProfile(c *gin.Context) {
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	viewer, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := pc.store.Users().FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	isSelf := viewer.ID == user.ID
+	isFriend := false
+	if !isSelf {
+		// Blocked users cannot see each other's profiles
+		blocked, err := isBlocked(database.DB, viewer.ID, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+			return
+		}
+		if blocked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		friendship, err := friendshipBetween(database.DB, viewer.ID, user.ID)
+		isFriend = err == nil && friendship.Status == models.FriendshipAccepted
+	}
+
+	canSee := func(v models.Visibility) bool {
+		switch {
+		case isSelf:
+			return true
+		case v == models.VisibilityPublic:
+			return true
+		case v == models.VisibilityFriends:
+			return isFriend
+		default:
+			return false
+		}
+	}
+
+	profile := gin.H{
+		"userId":      user.ID,
+		"displayName": user.DisplayName,
+		"photoURL":    user.PhotoURL,
+		"memberSince": user.CreatedAt,
+		"isFriend":    isFriend,
+	}
+	if canSee(user.EmailVisibility) {
+		profile["email"] = user.Email
+	}
+	if canSee(user.PhoneVisibility) {
+		profile["phone"] = user.Phone
+	}
+	if canSee(user.CountryVisibility) {
+		profile["country"] = user.Country
+	}
+	if canSee(user.BioVisibility) {
+		profile["bio"] = user.Bio
+	}
+	if canSee(user.RatingVisibility) {
+		profile["rating"] = user.Rating
+	}
+	if canSee(user.AchievementsVisibility) {
+		earned, err := pc.store.Achievements().ListEarned(c.Request.Context(), user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve achievements"})
+			return
+		}
+		profile["badges"] = achievements.Views(earned)
+	}
+	if canSee(user.StatsVisibility) {
+		stats, err := userStats(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats"})
+			return
+		}
+		profile["stats"] = stats
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+// UpdatePrivacySettings changes who can see each optional field of the caller's public profile
+func (pc *ProfileController) UpdatePrivacySettings(c *gin.Context) {
+	var input models.UpdatePrivacyRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Write only the provided visibility columns; the rest of the row may have changed
+	// since the auth middleware loaded it
+	user, err := pc.store.Users().UpdateProfile(c.Request.Context(), user.ID, repository.ProfileUpdate{
+		EmailVisibility:        input.Email,
+		PhoneVisibility:        input.Phone,
+		CountryVisibility:      input.Country,
+		BioVisibility:          input.Bio,
+		RatingVisibility:       input.Rating,
+		AchievementsVisibility: input.Achievements,
+		StatsVisibility:        input.Stats,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Privacy settings updated successfully",
+		"privacy": user.PrivacySettings(),
+	})
+}
+
+// userStats summarizes a user's activity across contests and room battles
+func userStats(userID uint) (gin.H, error) {
+	var submissions, correct, solved int64
+	if err := database.DB.Model(&models.Submission{}).Where("user_id = ?", userID).Count(&submissions).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.Submission{}).Where("user_id = ? AND is_correct", userID).Count(&correct).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.Submission{}).Where("user_id = ? AND is_correct", userID).
+		Distinct("question_id").Count(&solved).Error; err != nil {
+		return nil, err
+	}
+
+	var contests, battles, wins int64
+	if err := database.DB.Model(&models.ContestRegistration{}).Where("user_id = ?", userID).Count(&contests).Error; err != nil {
+		return nil, err
+	}
+	finishedRooms := database.DB.Model(&models.RoomParticipant{}).
+		Joins("JOIN rooms ON rooms.id = room_participants.room_id").
+		Where("room_participants.user_id = ? AND rooms.status = ?", userID, models.RoomFinished).
+		Session(&gorm.Session{})
+	if err := finishedRooms.Count(&battles).Error; err != nil {
+		return nil, err
+	}
+	if err := finishedRooms.Where("room_participants.rank = 1").Count(&wins).Error; err != nil {
+		return nil, err
+	}
+
+	accuracy := 0.0
+	if submissions > 0 {
+		accuracy = float64(correct) / float64(submissions)
+	}
+
+	return gin.H{
+		"solved":      solved,
+		"submissions": submissions,
+		"accuracy":    accuracy,
+		"contests":    contests,
+		"battles":     battles,
+		"battleWins":  wins,
+	}, nil
+}
@@ -2,19 +2,32 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
-	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
-type QuestionController struct{}
+type QuestionController struct {
+	store     repository.Store
+	generator config.QuestionGenerator
+}
+
+// NewQuestionController creates a new instance of QuestionController
+func NewQuestionController(store repository.Store, generator config.QuestionGenerator) *QuestionController {
+	return &QuestionController{
+		store:     store,
+		generator: generator,
+	}
+}
 
 // CreateQuestionWithGemini generates and creates a new math question using Gemini API
 func (qc *QuestionController) CreateQuestionWithGemini(c *gin.Context) {
@@ -38,19 +51,21 @@ func (qc *QuestionController) CreateQuestionWithGemini(c *gin.Context) {
 	}
 
 	// Get the admin user who is creating the question
-	adminUser, exists := c.Get("dbUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	admin, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	admin := adminUser.(models.User)
 
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Bound the Gemini call; the request context carries the request ID into its logs
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
 	// Generate question using Gemini API
-	generatedQuestion, err := config.GenerateQuestion(ctx, input.Category, input.Difficulty)
+	generatedQuestion, err := qc.generator.GenerateQuestion(ctx, input.Category, input.Difficulty)
+	if errors.Is(err, config.ErrGeneratorDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Question generation is not configured"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate question: %v", err)})
 		return
@@ -64,10 +79,12 @@ func (qc *QuestionController) CreateQuestionWithGemini(c *gin.Context) {
 	}
 
 	// Save to database
-	if err := database.DB.Create(&question).Error; err != nil {
+	err = qc.saveQuestion(c, admin, &question)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save question to database"})
 		return
 	}
+	metrics.QuestionsCreated.WithLabelValues("gemini").Inc()
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Question created successfully",
@@ -75,11 +92,20 @@ func (qc *QuestionController) CreateQuestionWithGemini(c *gin.Context) {
 	})
 }
 
+// saveQuestion stores a new question along with its audit entry
+func (qc *QuestionController) saveQuestion(c *gin.Context, author models.User, question *models.Question) error {
+	return qc.store.Transaction(c.Request.Context(), func(tx repository.Store) error {
+		if err := tx.Questions().Create(c.Request.Context(), question); err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), author, auditQuestionCreate, "question", question.QuestionID, nil, question)
+	})
+}
+
 // ListQuestions retrieves all questions
 func (qc *QuestionController) ListQuestions(c *gin.Context) {
-	var questions []models.Question
-
-	if err := database.DB.Find(&questions).Error; err != nil {
+	questions, err := qc.store.Questions().List(c.Request.Context(), "")
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve questions"})
 		return
 	}
@@ -94,8 +120,8 @@ func (qc *QuestionController) ListQuestions(c *gin.Context) {
 func (qc *QuestionController) GetQuestion(c *gin.Context) {
 	id := c.Param("id")
 
-	var question models.Question
-	if err := database.DB.Where("question_id = ?", id).First(&question).Error; err != nil {
+	question, err := qc.store.Questions().FindByQuestionID(c.Request.Context(), id)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
 		return
 	}
@@ -107,8 +133,8 @@ func (qc *QuestionController) GetQuestion(c *gin.Context) {
 func (qc *QuestionController) GetQuestionsByCategory(c *gin.Context) {
 	category := c.Param("category")
 
-	var questions []models.Question
-	if err := database.DB.Where("category = ?", category).Find(&questions).Error; err != nil {
+	questions, err := qc.store.Questions().List(c.Request.Context(), category)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve questions"})
 		return
 	}
@@ -238,12 +264,10 @@ func (qc *QuestionController) CreateManualQuestion(c *gin.Context) {
 	}
 
 	// Get the admin user who is creating the question
-	adminUser, exists := c.Get("dbUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	admin, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	admin := adminUser.(models.User)
 
 	// Set default values if not provided
 	expectedTime := input.ExpectedTime
@@ -276,10 +300,12 @@ func (qc *QuestionController) CreateManualQuestion(c *gin.Context) {
 	}
 
 	// Save to database
-	if err := database.DB.Create(&question).Error; err != nil {
+	err := qc.saveQuestion(c, admin, &question)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save question to database"})
 		return
 	}
+	metrics.QuestionsCreated.WithLabelValues("manual").Inc()
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Question created successfully",
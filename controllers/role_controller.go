@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	provider identity.IdentityProvider
+	store    repository.Store
+}
+
+// NewRoleController creates a new instance of RoleController
+func NewRoleController(provider identity.IdentityProvider, store repository.Store) *RoleController {
+	return &RoleController{
+		provider: provider,
+		store:    store,
+	}
+}
+
+// ListRoles returns every role with the permissions it grants
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	roles := make([]gin.H, 0, len(rbac.Roles))
+	for _, role := range rbac.Roles {
+		roles = append(roles, gin.H{
+			"role":        role,
+			"permissions": rbac.Permissions[role],
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// GetUserRoles returns a user's roles
+func (rc *RoleController) GetUserRoles(c *gin.Context) {
+	target, ok := loadTargetUser(c, rc.store.Users())
+	if !ok {
+		return
+	}
+
+	roles, err := rc.store.Users().Roles(c.Request.Context(), target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId": target.ID,
+		"roles":  roles,
+	})
+}
+
+// GrantRole gives a user an additional role
+func (rc *RoleController) GrantRole(c *gin.Context) {
+	var input models.GrantRoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rc.changeRole(c, input.Role, rbac.ActionGrant)
+}
+
+// RevokeRole removes a role from a user
+func (rc *RoleController) RevokeRole(c *gin.Context) {
+	rc.changeRole(c, c.Param("role"), rbac.ActionRevoke)
+}
+
+func (rc *RoleController) changeRole(c *gin.Context, name, action string) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := loadTargetUser(c, rc.store.Users())
+	if !ok {
+		return
+	}
+
+	role, err := rbac.Parse(name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if action == rbac.ActionGrant && role == rbac.RoleAdmin {
+		if err := checkVerified(target, verifiedForAdmin); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "User must verify their email address before being promoted"})
+			return
+		}
+	}
+
+	auditAction := auditRoleGrant
+	if action == rbac.ActionRevoke {
+		auditAction = auditRoleRevoke
+	}
+	ctx := c.Request.Context()
+	err = rc.store.Transaction(ctx, func(tx repository.Store) error {
+		before, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		if action == rbac.ActionGrant {
+			err = tx.Users().GrantRole(ctx, actor.ID, target.ID, role)
+		} else {
+			err = tx.Users().RevokeRole(ctx, actor.ID, target.ID, role)
+		}
+		if err != nil {
+			return err
+		}
+		after, err := userSnapshot(c, tx.Users(), target.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx.Audit(), actor, auditAction, "user", target.ID, before, after)
+	})
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	roles, err := rc.store.Users().Roles(ctx, target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles"})
+		return
+	}
+
+	if action == rbac.ActionRevoke {
+		if err := applyRoleRemoval(ctx, rc.provider, rc.store.Users(), target); err != nil {
+			slog.ErrorContext(ctx, "Failed to apply role revocation to the identity provider", "user_id", target.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": roleRemovalFailed})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Role revoked",
+			"userId":  target.ID,
+			"roles":   roles,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Role granted",
+		"userId":       target.ID,
+		"roles":        roles,
+		"claimsSynced": syncRoleClaims(c, rc.provider, rc.store.Users(), target),
+	})
+}
+
+// roleRemovalFailed is returned when roles were removed in the database but the
+// identity provider could not be updated. Tokens already issued keep the old roles
+// claim until they expire, so the failure must not be reported as success.
+const roleRemovalFailed = "Roles were removed, but the user's tokens could not be updated; run reconcile-roles"
+
+// applyRoleRemoval mirrors removed roles into the user's claims and revokes their refresh
+// tokens, so no session minted with the old roles can be renewed
+func applyRoleRemoval(ctx context.Context, provider identity.IdentityProvider, users repository.UserRepository, user models.User) error {
+	roles, err := users.Roles(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := rbac.SyncClaims(ctx, provider, user, roles); err != nil {
+		return fmt.Errorf("syncing claims: %w", err)
+	}
+	if err := provider.RevokeRefreshTokens(ctx, user.FirebaseUID); err != nil {
+		return fmt.Errorf("revoking refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// syncRoleClaims mirrors the user's roles into their token claims after a grant.
+// The database change stands either way; a failure is logged and left for the
+// reconcile-roles command to repair.
+func syncRoleClaims(c *gin.Context, provider identity.IdentityProvider, users repository.UserRepository, user models.User) bool {
+	roles, err := users.Roles(c.Request.Context(), user.ID)
+	if err == nil {
+		err = rbac.SyncClaims(c.Request.Context(), provider, user, roles)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to sync role claims", "user_id", user.ID, "error", err)
+		return false
+	}
+	return true
+}
+
+// ListRoleChanges returns the role change history, newest first,
+// optionally limited to one user with ?userId=
+func (rc *RoleController) ListRoleChanges(c *gin.Context) {
+	var targetID uint
+	if userID := c.Query("userId"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userId"})
+			return
+		}
+		targetID = uint(id)
+	}
+
+	changes, err := rc.store.Users().RoleChanges(c.Request.Context(), targetID, 200)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve role changes"})
+		return
+	}
+
+	views := make([]gin.H, 0, len(changes))
+	for _, change := range changes {
+		views = append(views, gin.H{
+			"actorId":   change.ActorID,
+			"targetId":  change.TargetID,
+			"role":      change.Role,
+			"action":    change.Action,
+			"createdAt": change.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": views})
+}
+
+// loadTargetUser loads the user named by the :id path parameter
+func loadTargetUser(c *gin.Context, users repository.UserRepository) (models.User, bool) {
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return models.User{}, false
+	}
+	user, err := users.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+func respondRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, rbac.ErrUnknownRole), errors.Is(err, rbac.ErrImplicitRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, rbac.ErrRoleHeld), errors.Is(err, rbac.ErrRoleNotHeld), errors.Is(err, rbac.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+	}
+}
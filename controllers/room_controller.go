@@ -0,0 +1,728 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	inviteCodeLength   = 6
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O or 1/I to avoid misreading
+	ratingKFactor      = 32
+)
+
+type RoomController struct {
+	store repository.Store
+}
+
+// NewRoomController creates a new instance of RoomController
+func NewRoomController(store repository.Store) *RoomController {
+	return &RoomController{store: store}
+}
+
+// CreateRoom creates a private battle room hosted by the caller
+func (rc *RoomController) CreateRoom(c *gin.Context) {
+	var input models.CreateRoomRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	host, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if input.Ranked && !requireVerified(c, host, verifiedForRanked) {
+		return
+	}
+
+	room, err := createRoom(host, input, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Room created successfully",
+		"room":    roomView(room, time.Now()),
+	})
+}
+
+// JoinRoom adds the caller to a waiting room using its invite code
+func (rc *RoomController) JoinRoom(c *gin.Context) {
+	var input models.JoinRoomRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var room models.Room
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the room so the participant limit cannot be exceeded by concurrent joins
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("invite_code = ?", normalizeInviteCode(input.InviteCode)).
+			First(&room).Error; err != nil {
+			return errRoomNotFound
+		}
+		if room.Status != models.RoomWaiting {
+			return errRoomNotWaiting
+		}
+		if room.ChallengedID != nil && *room.ChallengedID != user.ID {
+			return errRoomReserved
+		}
+		if room.Ranked {
+			if err := checkVerified(user, verifiedForRanked); err != nil {
+				return err
+			}
+		}
+
+		var count int64
+		if err := tx.Model(&models.RoomParticipant{}).Where("room_id = ?", room.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) >= room.MaxParticipants {
+			return errRoomFull
+		}
+
+		participant := models.RoomParticipant{RoomID: room.ID, UserID: user.ID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("User").Create(&participant)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyInRoom
+		}
+		return nil
+	})
+	if err != nil {
+		respondRoomError(c, err, "Failed to join room")
+		return
+	}
+
+	room, err = findRoom(room.InviteCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Joined room successfully",
+		"room":    roomView(room, time.Now()),
+	})
+}
+
+// GetRoom returns the room settings, participants and the currently open question.
+// Only participants may see it, since it includes the open question.
+func (rc *RoomController) GetRoom(c *gin.Context) {
+	room, ok := rc.loadRoom(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !isParticipant(room, user.ID) {
+		respondRoomError(c, errNotInRoom, "Failed to retrieve room")
+		return
+	}
+
+	c.JSON(http.StatusOK, roomView(room, time.Now()))
+}
+
+// LeaveRoom removes the caller from a room that has not started yet
+func (rc *RoomController) LeaveRoom(c *gin.Context) {
+	room, ok := rc.loadRoom(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if room.HostID == user.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "The host cannot leave the room"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the room so a concurrent start sees the participant list after the leave
+		if err := lockWaitingRoom(tx, room.ID); err != nil {
+			return err
+		}
+		result := tx.Where("room_id = ? AND user_id = ?", room.ID, user.ID).Delete(&models.RoomParticipant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotInRoom
+		}
+		return nil
+	})
+	if errors.Is(err, errNotInRoom) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not a participant of this room"})
+		return
+	}
+	if err != nil {
+		respondRoomError(c, err, "Failed to leave room")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left room successfully"})
+}
+
+// StartRoom picks the questions and starts the battle. Only the host can start a room.
+func (rc *RoomController) StartRoom(c *gin.Context) {
+	room, ok := rc.loadRoom(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if room.HostID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can start the room"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the room so no one can join or leave between the count and the start
+		if err := lockWaitingRoom(tx, room.ID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.RoomParticipant{}).Where("room_id = ?", room.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count < 2 {
+			return errTooFewParticipants
+		}
+
+		query := tx.Model(&models.Question{})
+		if room.Category != "" {
+			query = query.Where("category = ?", room.Category)
+		}
+		if room.Difficulty != "" {
+			query = query.Where("difficulty = ?", room.Difficulty)
+		}
+
+		var questions []models.Question
+		if err := query.Order("RANDOM()").Limit(room.QuestionCount).Find(&questions).Error; err != nil {
+			return err
+		}
+		if len(questions) < room.QuestionCount {
+			return errNotEnoughQuestions
+		}
+
+		startedAt := time.Now()
+		err := tx.Model(&models.Room{}).
+			Where("id = ?", room.ID).
+			Updates(map[string]interface{}{"status": models.RoomInProgress, "started_at": startedAt}).Error
+		if err != nil {
+			return err
+		}
+
+		roomQuestions := make([]models.RoomQuestion, len(questions))
+		for i, q := range questions {
+			roomQuestions[i] = models.RoomQuestion{RoomID: room.ID, QuestionID: q.ID, Position: i + 1}
+		}
+		return tx.Omit("Question").Create(&roomQuestions).Error
+	})
+	if err != nil {
+		respondRoomError(c, err, "Failed to start room")
+		return
+	}
+
+	room, err = findRoom(room.InviteCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Room started",
+		"room":    roomView(room, time.Now()),
+	})
+}
+
+// SubmitRoomAnswer records the caller's single answer to the currently open question
+func (rc *RoomController) SubmitRoomAnswer(c *gin.Context) {
+	room, ok := rc.loadRoom(c)
+	if !ok {
+		return
+	}
+
+	var input models.SubmitRoomAnswerRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	index := room.CurrentQuestionIndex(time.Now())
+	if index < 0 || index >= len(room.Questions) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No question is currently open"})
+		return
+	}
+	current := room.Questions[index]
+	if current.Question.QuestionID != input.QuestionID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Question is not currently open"})
+		return
+	}
+
+	submission := models.Submission{
+		UserID:     user.ID,
+		QuestionID: current.QuestionID,
+		RoomID:     &room.ID,
+		Answer:     input.Answer,
+		IsCorrect:  answersMatch(input.Answer, current.Question.Answer),
+	}
+	if submission.IsCorrect {
+		submission.Points = current.Question.Points
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the participant row so each question can only be answered once
+		var participant models.RoomParticipant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("room_id = ? AND user_id = ?", room.ID, user.ID).
+			First(&participant).Error; err != nil {
+			return errNotInRoom
+		}
+
+		var answered int64
+		if err := tx.Model(&models.Submission{}).
+			Where("room_id = ? AND user_id = ? AND question_id = ?", room.ID, user.ID, current.QuestionID).
+			Count(&answered).Error; err != nil {
+			return err
+		}
+		if answered > 0 {
+			return errAlreadyAnswered
+		}
+
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+		if !submission.IsCorrect {
+			return nil
+		}
+		return tx.Model(&participant).Updates(map[string]interface{}{
+			"score":   gorm.Expr("score + ?", submission.Points),
+			"correct": gorm.Expr("correct + 1"),
+		}).Error
+	})
+	if err != nil {
+		respondRoomError(c, err, "Failed to save answer")
+		return
+	}
+	countSubmission("room", submission.IsCorrect)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"correct":              submission.IsCorrect,
+		"points":               submission.Points,
+		"achievementsUnlocked": unlockAchievements(c.Request.Context(), rc.store.Achievements(), user.ID, achievements.EventSubmission),
+	})
+}
+
+// GetRoomResults returns the final standings and answers once a room has finished
+func (rc *RoomController) GetRoomResults(c *gin.Context) {
+	room, ok := rc.loadRoom(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if room.Status != models.RoomFinished {
+		c.JSON(http.StatusConflict, gin.H{"error": "Room has not finished yet"})
+		return
+	}
+
+	var submissions []models.Submission
+	if err := database.DB.Where("room_id = ? AND is_correct", room.ID).Find(&submissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results"})
+		return
+	}
+	correctBy := make(map[uint]int)
+	for _, s := range submissions {
+		correctBy[s.QuestionID]++
+	}
+
+	participants := rankedParticipants(room.Participants)
+	standings := make([]gin.H, len(participants))
+	for i, p := range participants {
+		standings[i] = gin.H{
+			"rank":         p.Rank,
+			"userId":       p.UserID,
+			"displayName":  p.User.DisplayName,
+			"photoURL":     p.User.PhotoURL,
+			"score":        p.Score,
+			"correct":      p.Correct,
+			"ratingChange": p.RatingChange,
+		}
+	}
+
+	questions := make([]gin.H, len(room.Questions))
+	for i, q := range room.Questions {
+		questions[i] = gin.H{
+			"position":    q.Position,
+			"questionId":  q.Question.QuestionID,
+			"title":       q.Question.Title,
+			"answer":      q.Question.Answer,
+			"explanation": q.Question.Explanation,
+			"points":      q.Question.Points,
+			"correctBy":   correctBy[q.QuestionID],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"inviteCode":           room.InviteCode,
+		"ranked":               room.Ranked,
+		"startedAt":            room.StartedAt,
+		"finishedAt":           room.FinishedAt,
+		"standings":            standings,
+		"questions":            questions,
+		"achievementsUnlocked": announceAchievements(c.Request.Context(), rc.store.Achievements(), user.ID),
+	})
+}
+
+var (
+	errRoomNotFound       = errors.New("room not found")
+	errRoomNotWaiting     = errors.New("room is not waiting for players")
+	errRoomFull           = errors.New("room is full")
+	errRoomReserved       = errors.New("room is reserved for a challenged user")
+	errAlreadyInRoom      = errors.New("already in room")
+	errNotInRoom          = errors.New("not in room")
+	errAlreadyAnswered    = errors.New("already answered")
+	errNotEnoughQuestions = errors.New("not enough questions")
+	errTooFewParticipants = errors.New("too few participants")
+)
+
+// respondRoomError maps room errors to HTTP responses
+func respondRoomError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errRoomNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+	case errors.Is(err, errRoomNotWaiting):
+		c.JSON(http.StatusConflict, gin.H{"error": "Room has already started"})
+	case errors.Is(err, errRoomFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "Room is full"})
+	case errors.Is(err, errRoomReserved):
+		c.JSON(http.StatusForbidden, gin.H{"error": "This room is reserved for a challenged friend"})
+	case errors.Is(err, errEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
+	case errors.Is(err, errAlreadyInRoom):
+		c.JSON(http.StatusConflict, gin.H{"error": "Already in this room"})
+	case errors.Is(err, errNotInRoom):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not a participant of this room"})
+	case errors.Is(err, errAlreadyAnswered):
+		c.JSON(http.StatusConflict, gin.H{"error": "Question already answered"})
+	case errors.Is(err, errTooFewParticipants):
+		c.JSON(http.StatusConflict, gin.H{"error": "At least two participants are required"})
+	case errors.Is(err, errNotEnoughQuestions):
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough questions match the room settings"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// createRoom creates a room with a fresh invite code and adds the host as the first participant.
+// A non-nil challenged user reserves the remaining seat for that user.
+func createRoom(host models.User, input models.CreateRoomRequest, challenged *uint) (models.Room, error) {
+	room := models.Room{
+		ChallengedID:       challenged,
+		HostID:             host.ID,
+		Category:           input.Category,
+		Difficulty:         input.Difficulty,
+		QuestionCount:      input.QuestionCount,
+		SecondsPerQuestion: input.SecondsPerQuestion,
+		MaxParticipants:    input.MaxParticipants,
+		Ranked:             input.Ranked,
+		Status:             models.RoomWaiting,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		code, err := generateInviteCode(tx)
+		if err != nil {
+			return err
+		}
+		room.InviteCode = code
+
+		if err := tx.Create(&room).Error; err != nil {
+			return err
+		}
+
+		host := models.RoomParticipant{RoomID: room.ID, UserID: host.ID}
+		return tx.Omit("User").Create(&host).Error
+	})
+	if err != nil {
+		return room, err
+	}
+
+	return findRoom(room.InviteCode)
+}
+
+// generateInviteCode returns a random invite code that is not used by another room
+func generateInviteCode(tx *gorm.DB) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code := make([]byte, inviteCodeLength)
+		for i := range code {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(inviteCodeAlphabet))))
+			if err != nil {
+				return "", err
+			}
+			code[i] = inviteCodeAlphabet[n.Int64()]
+		}
+
+		var count int64
+		if err := tx.Model(&models.Room{}).Where("invite_code = ?", string(code)).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return string(code), nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate a unique invite code")
+}
+
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// findRoom loads a room by invite code with its participants and questions
+func findRoom(inviteCode string) (models.Room, error) {
+	var room models.Room
+	err := database.DB.
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Participants.User").
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Questions.Question").
+		Where("invite_code = ?", normalizeInviteCode(inviteCode)).
+		First(&room).Error
+	return room, err
+}
+
+// lockWaitingRoom locks the room row for the rest of the transaction and checks
+// that it is still waiting for players
+func lockWaitingRoom(tx *gorm.DB, roomID uint) error {
+	var room models.Room
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, roomID).Error; err != nil {
+		return errRoomNotFound
+	}
+	if room.Status != models.RoomWaiting {
+		return errRoomNotWaiting
+	}
+	return nil
+}
+
+// isParticipant reports whether the user has joined the room
+func isParticipant(room models.Room, userID uint) bool {
+	for _, p := range room.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRoom fetches the room named by the :code path parameter, finishing it first if its time is up
+func (rc *RoomController) loadRoom(c *gin.Context) (models.Room, bool) {
+	room, err := findRoom(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return room, false
+	}
+
+	if room.Status == models.RoomInProgress && !time.Now().Before(*room.EndsAt()) {
+		finished, err := finishRoom(room)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to finish room"})
+			return room, false
+		}
+		if finished {
+			for _, p := range room.Participants {
+				if _, err := rc.store.Achievements().Evaluate(c.Request.Context(), p.UserID, achievements.EventBattleFinished); err != nil {
+					slog.ErrorContext(c.Request.Context(), "Error evaluating achievements", "user_id", p.UserID, "error", err)
+				}
+			}
+		}
+		if room, err = findRoom(room.InviteCode); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room"})
+			return room, false
+		}
+	}
+
+	return room, true
+}
+
+// finishRoom marks a room as finished, stores final ranks and applies rating changes for ranked rooms.
+// It is safe to call concurrently; only the first caller finalizes the room and gets finished == true.
+func finishRoom(room models.Room) (finished bool, err error) {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Room{}).
+			Where("id = ? AND status = ?", room.ID, models.RoomInProgress).
+			Updates(map[string]interface{}{"status": models.RoomFinished, "finished_at": *room.EndsAt()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		finished = true
+
+		participants := rankedParticipants(room.Participants)
+		var changes map[uint]int
+		if room.Ranked {
+			changes = ratingChanges(participants)
+		}
+
+		for _, p := range participants {
+			if err := tx.Model(&models.RoomParticipant{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+				"rank":          p.Rank,
+				"rating_change": changes[p.UserID],
+			}).Error; err != nil {
+				return err
+			}
+			if changes[p.UserID] == 0 {
+				continue
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", p.UserID).
+				Update("rating", gorm.Expr("rating + ?", changes[p.UserID])).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return finished, err
+}
+
+// rankedParticipants orders participants by score and assigns shared ranks to ties
+func rankedParticipants(participants []models.RoomParticipant) []models.RoomParticipant {
+	ranked := make([]models.RoomParticipant, len(participants))
+	copy(ranked, participants)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Correct > ranked[j].Correct
+	})
+
+	for i := range ranked {
+		if i > 0 && ranked[i].Score == ranked[i-1].Score && ranked[i].Correct == ranked[i-1].Correct {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return ranked
+}
+
+// ratingChanges computes Elo rating deltas by treating the room as pairwise matches between all participants
+func ratingChanges(ranked []models.RoomParticipant) map[uint]int {
+	changes := make(map[uint]int, len(ranked))
+	if len(ranked) < 2 {
+		return changes
+	}
+
+	for i, p := range ranked {
+		var delta float64
+		for j, opponent := range ranked {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, float64(opponent.User.Rating-p.User.Rating)/400))
+			actual := 0.5
+			if p.Rank < opponent.Rank {
+				actual = 1
+			} else if p.Rank > opponent.Rank {
+				actual = 0
+			}
+			delta += actual - expected
+		}
+		changes[p.UserID] = int(math.Round(ratingKFactor * delta / float64(len(ranked)-1)))
+	}
+	return changes
+}
+
+// roomView builds the JSON representation of a room without revealing answers
+func roomView(room models.Room, now time.Time) gin.H {
+	participants := make([]gin.H, len(room.Participants))
+	for i, p := range room.Participants {
+		participants[i] = gin.H{
+			"userId":      p.UserID,
+			"displayName": p.User.DisplayName,
+			"photoURL":    p.User.PhotoURL,
+			"score":       p.Score,
+			"correct":     p.Correct,
+			"isHost":      p.UserID == room.HostID,
+		}
+	}
+
+	view := gin.H{
+		"inviteCode":         room.InviteCode,
+		"hostId":             room.HostID,
+		"category":           room.Category,
+		"difficulty":         room.Difficulty,
+		"questionCount":      room.QuestionCount,
+		"secondsPerQuestion": room.SecondsPerQuestion,
+		"maxParticipants":    room.MaxParticipants,
+		"ranked":             room.Ranked,
+		"challengedId":       room.ChallengedID,
+		"status":             room.Status,
+		"startedAt":          room.StartedAt,
+		"endsAt":             room.EndsAt(),
+		"participants":       participants,
+	}
+
+	if index := room.CurrentQuestionIndex(now); index >= 0 && index < len(room.Questions) {
+		q := room.Questions[index]
+		closesAt := room.StartedAt.Add(time.Duration((index+1)*room.SecondsPerQuestion) * time.Second)
+		view["currentQuestion"] = gin.H{
+			"position":   q.Position,
+			"questionId": q.Question.QuestionID,
+			"title":      q.Question.Title,
+			"question":   q.Question.Question,
+			"imageUrl":   q.Question.ImageUrl,
+			"points":     q.Question.Points,
+			"closesAt":   closesAt,
+		}
+	}
+
+	return view
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+)
+
+func TestRankedParticipants(t *testing.T) {
+	ranked := rankedParticipants([]models.RoomParticipant{
+		{UserID: 1, Score: 50, Correct: 1},
+		{UserID: 2, Score: 90, Correct: 2},
+		{UserID: 3, Score: 50, Correct: 1},
+		{UserID: 4, Score: 50, Correct: 2},
+	})
+
+	want := map[uint]int{2: 1, 4: 2, 1: 3, 3: 3}
+	for _, p := range ranked {
+		if p.Rank != want[p.UserID] {
+			t.Errorf("user %d ranked %d, want %d", p.UserID, p.Rank, want[p.UserID])
+		}
+	}
+	if ranked[0].UserID != 2 {
+		t.Errorf("expected the highest score first, got user %d", ranked[0].UserID)
+	}
+}
+
+func TestRatingChanges(t *testing.T) {
+	// player has a rating and a score; ranks come from rankedParticipants
+	type player struct {
+		rating, score int
+	}
+	tests := []struct {
+		name    string
+		players []player
+		want    []int
+	}{
+		{"single player", []player{{1200, 100}}, []int{0}},
+		{"equal ratings, first wins", []player{{1200, 100}, {1200, 50}}, []int{16, -16}},
+		{"equal ratings, tie", []player{{1200, 50}, {1200, 50}}, []int{0, 0}},
+		{"favourite ties", []player{{1400, 50}, {1200, 50}}, []int{-8, 8}},
+		{"favourite wins", []player{{1400, 100}, {1200, 50}}, []int{8, -8}},
+		{"underdog wins", []player{{1200, 100}, {1400, 50}}, []int{24, -24}},
+		{"three players", []player{{1200, 100}, {1200, 50}, {1200, 0}}, []int{16, 0, -16}},
+		{"three players, tie for first", []player{{1200, 100}, {1200, 100}, {1200, 0}}, []int{8, 8, -16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			participants := make([]models.RoomParticipant, len(tt.players))
+			for i, p := range tt.players {
+				id := uint(i + 1)
+				participants[i] = models.RoomParticipant{
+					UserID: id,
+					User:   models.User{ID: id, Rating: p.rating},
+					Score:  p.score,
+				}
+			}
+
+			changes := ratingChanges(rankedParticipants(participants))
+			for i, want := range tt.want {
+				if got := changes[uint(i+1)]; got != want {
+					t.Errorf("player %d: rating change %d, want %d", i+1, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// startSession records a signed-in device for the user's new refresh token
+func startSession(c *gin.Context, sessions repository.SessionRepository, userID uint, session *identity.Session) (models.UserSession, error) {
+	now := time.Now()
+	record := models.UserSession{
+		SessionID:        uuid.New().String(),
+		UserID:           userID,
+		RefreshTokenHash: hashToken(session.RefreshToken),
+		UserAgent:        truncate(c.Request.UserAgent(), 512),
+		IPAddress:        c.ClientIP(),
+		LastUsedAt:       now,
+	}
+	err := sessions.Create(c.Request.Context(), &record)
+	return record, err
+}
+
+// RefreshToken exchanges a refresh token for a new ID token.
+// The refresh token must belong to a session that has not been revoked.
+func (ac *AuthController) RefreshToken(c *gin.Context) {
+	var input models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	sessions := ac.store.Sessions()
+	record, err := sessions.FindActiveByTokenHash(ctx, hashToken(input.RefreshToken))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+
+	user, err := ac.store.Users().FindByID(ctx, record.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+	if user.SuspendedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	session, err := ac.provider.RefreshIDToken(ctx, input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidToken) {
+			// The provider no longer accepts this token, so the session is dead
+			if err := sessions.Revoke(ctx, record.ID, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "Failed to revoke dead session", "session_id", record.SessionID, "error", err)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error communicating with authentication service"})
+		return
+	}
+	if session.UID != user.FirebaseUID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+
+	// Rotate the stored hash, unless another refresh or a revocation got there first
+	err = sessions.Rotate(ctx, record.ID, record.RefreshTokenHash, repository.SessionRotation{
+		RefreshTokenHash: hashToken(session.RefreshToken),
+		UserAgent:        truncate(c.Request.UserAgent(), 512),
+		IPAddress:        c.ClientIP(),
+		LastUsedAt:       time.Now(),
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        session.IDToken,
+		"refreshToken": session.RefreshToken,
+		"expiresIn":    session.ExpiresIn,
+		"sessionId":    record.SessionID,
+	})
+}
+
+// ListSessions returns the caller's active sessions, most recently used first
+func (ac *AuthController) ListSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := ac.store.Sessions().ListActive(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	views := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, sessionView(session))
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": views})
+}
+
+// RevokeSession signs one of the caller's devices out. The identity provider can only
+// revoke an account's refresh tokens all at once, so every session is revoked with it
+// and the caller's other devices must sign in again; their ID tokens stop working too.
+func (ac *AuthController) RevokeSession(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := ac.store.Sessions().FindActive(ctx, user.ID, c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errSessionNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	// Without this the refresh token would keep working against the provider directly
+	if err := ac.provider.RevokeRefreshTokens(ctx, user.FirebaseUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke tokens with authentication service"})
+		return
+	}
+
+	revoked, err := ac.store.Sessions().RevokeAll(ctx, user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked",
+		"revoked": revoked,
+	})
+}
+
+// RevokeAllSessions signs the caller out everywhere, revoking their refresh
+// tokens with the identity provider as well
+func (ac *AuthController) RevokeAllSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := ac.provider.RevokeRefreshTokens(c.Request.Context(), user.FirebaseUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke tokens with authentication service"})
+		return
+	}
+
+	revoked, err := ac.store.Sessions().RevokeAll(c.Request.Context(), user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All sessions revoked",
+		"revoked": revoked,
+	})
+}
+
+func sessionView(session models.UserSession) gin.H {
+	return gin.H{
+		"id":         session.SessionID,
+		"userAgent":  session.UserAgent,
+		"ipAddress":  session.IPAddress,
+		"createdAt":  session.CreatedAt,
+		"lastUsedAt": session.LastUsedAt,
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/mailer"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	emailVerificationTTL      = 24 * time.Hour
+	emailVerificationCooldown = time.Minute
+)
+
+// Actions that REQUIRE_VERIFIED_EMAIL (a comma-separated list) can restrict to verified accounts
+const (
+	verifiedForRanked = "ranked" // contest registration and ranked rooms
+	verifiedForAdmin  = "admin"  // promotion to admin
+)
+
+var (
+	errEmailNotVerified     = errors.New("a verified email address is required")
+	errVerificationInvalid  = errors.New("invalid or expired verification link")
+	errVerificationCooldown = errors.New("a verification email was sent recently")
+)
+
+// checkVerified returns errEmailNotVerified if the policy requires a verified email
+// for the action and the user has not verified theirs
+func checkVerified(user models.User, action string) error {
+	if user.EmailVerified {
+		return nil
+	}
+	for _, required := range config.Current.RequireVerifiedEmail {
+		if required == action {
+			return errEmailNotVerified
+		}
+	}
+	return nil
+}
+
+// requireVerified writes a 403 response and returns false when checkVerified fails
+func requireVerified(c *gin.Context, user models.User, action string) bool {
+	if err := checkVerified(user, action); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
+		return false
+	}
+	return true
+}
+
+// sendVerificationEmail replaces any outstanding verification link for the user and emails a new one
+func sendVerificationEmail(ctx context.Context, users repository.UserRepository, sender mailer.Sender, user models.User) error {
+	token, err := generateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	err = users.ReplaceEmailVerification(ctx, &models.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}, emailVerificationCooldown)
+	if errors.Is(err, repository.ErrCooldown) {
+		return errVerificationCooldown
+	}
+	if err != nil {
+		return err
+	}
+
+	link := config.Current.PublicURL + "/api/v1/auth/verify-email?token=" + url.QueryEscape(token)
+	return sender.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your ThinkBattleground email address",
+		Body: "Confirm your email address by opening the link below. It expires in 24 hours.\n\n" +
+			link + "\n\nIf you did not create an account, you can ignore this email.\n",
+	})
+}
+
+// SendVerificationEmail emails the caller a new verification link
+func (ac *AuthController) SendVerificationEmail(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.EmailVerified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(c.Request.Context(), ac.store.Users(), ac.mailer, user); err != nil {
+		if errors.Is(err, errVerificationCooldown) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait a minute before requesting another email"})
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Failed to send verification email", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// emailConfirmationPage is served for the emailed link. Opening the link only shows
+// this form; mail scanners and link previews fetch it without consuming the token.
+var emailConfirmationPage = template.Must(template.New("confirm-email").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Verify your email address</title></head>
+<body>
+<h1>Verify your email address</h1>
+{{if .Token}}<form method="post" action="verify-email">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Confirm my email address</button>
+</form>{{else}}<p>{{.Message}}</p>{{end}}
+</body>
+</html>
+`))
+
+// ShowEmailConfirmation renders the confirmation form for a verification link
+func (ac *AuthController) ShowEmailConfirmation(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		renderEmailConfirmation(c, http.StatusBadRequest, "", errVerificationInvalid.Error())
+		return
+	}
+	renderEmailConfirmation(c, http.StatusOK, token, "")
+}
+
+// ConfirmEmail verifies an email address with the token from a verification link,
+// sent as JSON or by the confirmation form. Form submissions get a page back.
+func (ac *AuthController) ConfirmEmail(c *gin.Context) {
+	form := c.ContentType() == binding.MIMEPOSTForm
+	respond := func(status int, body gin.H) {
+		if !form {
+			c.JSON(status, body)
+			return
+		}
+		if message, ok := body["error"].(string); ok {
+			renderEmailConfirmation(c, status, "", message)
+			return
+		}
+		renderEmailConfirmation(c, status, "", "Your email address is verified. You can close this page.")
+	}
+
+	var input models.VerifyEmailRequest
+	if err := c.ShouldBind(&input); err != nil {
+		respond(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Consume the token so each link works once
+	users := ac.store.Users()
+	record, err := users.ConsumeEmailVerification(c.Request.Context(), hashToken(input.Token))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		respond(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+	if err != nil || time.Now().After(record.ExpiresAt) {
+		respond(http.StatusBadRequest, gin.H{"error": errVerificationInvalid.Error()})
+		return
+	}
+
+	user, err := users.FindByID(c.Request.Context(), record.UserID)
+	if err != nil || user.Email != record.Email {
+		// The account is gone or its address changed since the link was sent
+		respond(http.StatusBadRequest, gin.H{"error": errVerificationInvalid.Error()})
+		return
+	}
+
+	if err := ac.provider.SetEmailVerified(c.Request.Context(), user.FirebaseUID, true); err != nil {
+		respond(http.StatusInternalServerError, gin.H{"error": "Failed to update authentication service"})
+		return
+	}
+	if err := users.SetEmailVerified(c.Request.Context(), user.ID, true); err != nil {
+		respond(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	respond(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+		"email":   user.Email,
+	})
+}
+
+// renderEmailConfirmation writes the confirmation page with either the form for token
+// or a message. The page is not cached and sends no referrer, since its URL holds the token.
+func renderEmailConfirmation(c *gin.Context, status int, token, message string) {
+	var page bytes.Buffer
+	if err := emailConfirmationPage.Execute(&page, struct{ Token, Message string }{token, message}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render page"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
@@ -1,11 +1,15 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
-	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/logging"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/tracing"
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,27 +17,85 @@ import (
 
 var DB *gorm.DB
 
-func InitDB() error {
-	dbHost := config.GetEnv("POSTGRES_HOST", "localhost")
-	dbUser := config.GetEnv("POSTGRES_USER", "postgres")
-	dbPassword := config.GetEnv("POSTGRES_PASSWORD", "")
-	dbName := config.GetEnv("POSTGRES_DB", "thinkbattleground")
-	dbPort := config.GetEnv("POSTGRES_PORT", "5432")
+// slowQueryThreshold is the duration above which queries are logged as warnings
+const slowQueryThreshold = 200 * time.Millisecond
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
+// Connect opens the database without touching the schema
+func Connect(cfg config.DatabaseConfig) error {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
+		dsnValue(cfg.Host), dsnValue(cfg.User), dsnValue(cfg.Password), dsnValue(cfg.Name), cfg.Port)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey for the repositories
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logging.NewGormLogger(slowQueryThreshold),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %v", err)
 	}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return fmt.Errorf("failed to install query tracing: %v", err)
+	}
 
-	// Auto-migrate the models
-	if err = db.AutoMigrate(&models.User{}, &models.Question{}); err != nil {
+	DB = db
+	return nil
+}
+
+// Ping checks the database is reachable and returns the connection pool statistics
+func Ping(ctx context.Context) (map[string]interface{}, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := sqlDB.Stats()
+	details := map[string]interface{}{
+		"openConnections": stats.OpenConnections,
+		"inUse":           stats.InUse,
+		"idle":            stats.Idle,
+		"maxOpen":         stats.MaxOpenConnections,
+		"waitCount":       stats.WaitCount,
+	}
+	return details, sqlDB.PingContext(ctx)
+}
+
+// Close closes the connection pool, waiting for queries in progress
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// dsnValue quotes a connection string value so empty values and spaces parse correctly
+func dsnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// InitDB connects and applies pending migrations. Set AUTO_MIGRATE=false to
+// leave the schema alone and run `migrate up` as a separate deploy step.
+func InitDB(cfg config.DatabaseConfig) error {
+	if err := Connect(cfg); err != nil {
+		return err
+	}
+
+	if !cfg.AutoMigrate {
+		slog.Info("AUTO_MIGRATE is false, skipping database migrations")
+		return nil
+	}
+
+	applied, err := MigrateUp(DB)
+	if err != nil {
 		return fmt.Errorf("failed to migrate database: %v", err)
 	}
 
-	DB = db
-	log.Println("Database migration completed successfully")
+	slog.Info("Database migration completed successfully", "applied", len(applied))
 	return nil
 }
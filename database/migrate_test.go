@@ -0,0 +1,235 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// allModels lists every table the application reads, so the tests catch a model
+// field that no migration creates
+var allModels = []interface{}{
+	&models.User{}, &models.Question{},
+	&models.Contest{}, &models.ContestQuestion{}, &models.ContestRegistration{}, &models.Submission{},
+	&models.Room{}, &models.RoomParticipant{}, &models.RoomQuestion{},
+	&models.UserAchievement{}, &models.Friendship{}, &models.UserBlock{},
+	&models.OAuthState{}, &models.UserSession{}, &models.EmailVerification{},
+	&models.UserRole{}, &models.RoleChange{}, &models.AuditLog{},
+}
+
+// legacyUser is the users table as the original AutoMigrate created it
+type legacyUser struct {
+	ID          uint   `gorm:"primaryKey"`
+	FirebaseUID string `gorm:"unique;not null"`
+	Email       string `gorm:"unique;not null"`
+	IsAdmin     bool   `gorm:"default:false"`
+	DisplayName string `gorm:"size:255"`
+	PhotoURL    string `gorm:"size:512"`
+	Phone       string `gorm:"size:20"`
+	Country     string `gorm:"size:100"`
+	Bio         string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (legacyUser) TableName() string {
+	return "users"
+}
+
+// testDB opens TEST_DATABASE_URL (a postgres:// URL) in a schema of its own, which is
+// dropped when the test ends. The test is skipped when the variable is not set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL must be a postgres:// URL: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := gorm.Open(postgres.Open(u.String()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// expectModelColumns fails the test for every model field without a column
+func expectModelColumns(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, model := range allModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parsing %T: %v", model, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("%s has no column %s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db := testDB(t)
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	expectModelColumns(t, db)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateDown(db, len(migrations)); err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp after rolling back: %v", err)
+	}
+	expectModelColumns(t, db)
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db := testDB(t)
+
+	// A database from before versioned migrations, with an existing admin
+	if err := db.AutoMigrate(&legacyUser{}, &models.Question{}); err != nil {
+		t.Fatalf("creating the legacy schema: %v", err)
+	}
+	legacy := legacyUser{FirebaseUID: "legacy-uid", Email: "legacy@example.com", IsAdmin: true}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("creating legacy user: %v", err)
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	expectModelColumns(t, db)
+
+	var user models.User
+	if err := db.First(&user, legacy.ID).Error; err != nil {
+		t.Fatalf("loading the legacy user: %v", err)
+	}
+	if user.Rating != 1200 || user.EmailVisibility != models.VisibilityPrivate || user.StatsVisibility != models.VisibilityPublic {
+		t.Errorf("expected new columns to take their defaults, got rating %d, email %q, stats %q",
+			user.Rating, user.EmailVisibility, user.StatsVisibility)
+	}
+
+	var roles int64
+	if err := db.Model(&models.UserRole{}).Where("user_id = ? AND role = ?", legacy.ID, "admin").Count(&roles).Error; err != nil {
+		t.Fatal(err)
+	}
+	if roles != 1 {
+		t.Errorf("expected the legacy admin to be backfilled with the admin role, got %d rows", roles)
+	}
+}
+
+func TestFriendshipPairIsUnordered(t *testing.T) {
+	db := testDB(t)
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+
+	a := models.User{FirebaseUID: "uid-a", Email: "a@example.com"}
+	b := models.User{FirebaseUID: "uid-b", Email: "b@example.com"}
+	if err := db.Create(&a).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&b).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	forward := models.Friendship{RequesterID: a.ID, AddresseeID: b.ID, Status: models.FriendshipPending}
+	if err := db.Omit("Requester", "Addressee").Create(&forward).Error; err != nil {
+		t.Fatalf("creating the first request: %v", err)
+	}
+	reverse := models.Friendship{RequesterID: b.ID, AddresseeID: a.ID, Status: models.FriendshipPending}
+	if err := db.Omit("Requester", "Addressee").Create(&reverse).Error; err == nil {
+		t.Error("expected a request in the opposite direction to violate the unique pair index")
+	}
+}
+
+func TestBattleWinIgnoresSharedFirstPlace(t *testing.T) {
+	db := testDB(t)
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+
+	a := models.User{FirebaseUID: "uid-a", Email: "a@example.com"}
+	b := models.User{FirebaseUID: "uid-b", Email: "b@example.com"}
+	for _, user := range []*models.User{&a, &b} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	finish := func(code string, rankA, rankB int) {
+		t.Helper()
+		room := models.Room{InviteCode: code, HostID: a.ID, QuestionCount: 1, SecondsPerQuestion: 30, MaxParticipants: 2, Status: models.RoomFinished}
+		if err := db.Omit("Participants", "Questions").Create(&room).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []models.RoomParticipant{{RoomID: room.ID, UserID: a.ID, Rank: rankA}, {RoomID: room.ID, UserID: b.ID, Rank: rankB}} {
+			if err := db.Omit("User").Create(&p).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	finish("TIED", 1, 1)
+	unlocked, err := achievements.Evaluate(db, a.ID, achievements.EventBattleFinished)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(unlocked) != 0 {
+		t.Fatalf("a shared first place counted as a win: %v", unlocked)
+	}
+
+	finish("WON", 1, 2)
+	unlocked, err = achievements.Evaluate(db, a.ID, achievements.EventBattleFinished)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(unlocked) != 1 || unlocked[0].Code != "first_battle_win" {
+		t.Errorf("expected first_battle_win for a sole first place, got %v", unlocked)
+	}
+}
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS
+    audit_logs,
+    role_changes,
+    user_roles,
+    email_verifications,
+    user_sessions,
+    oauth_states,
+    user_blocks,
+    friendships,
+    user_achievements,
+    room_questions,
+    room_participants,
+    rooms,
+    submissions,
+    contest_registrations,
+    contest_questions,
+    contests,
+    questions,
+    users;
@@ -0,0 +1,252 @@
+-- Baseline schema. users and questions are exactly as the original AutoMigrate
+-- created them; the other tables are as AutoMigrate created them when this series
+-- began. Every statement is IF NOT EXISTS, so an existing table is left untouched
+-- here and 0004 adds the columns introduced since.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    firebase_uid text NOT NULL CONSTRAINT uni_users_firebase_uid UNIQUE,
+    email text NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    is_admin boolean DEFAULT false,
+    display_name varchar(255),
+    photo_url varchar(512),
+    phone varchar(20),
+    country varchar(100),
+    bio text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id bigserial PRIMARY KEY,
+    question_id text NOT NULL,
+    title varchar(255) NOT NULL,
+    question text NOT NULL,
+    answer text NOT NULL,
+    explanation text NOT NULL,
+    hints text[],
+    difficulty varchar(20) NOT NULL,
+    expected_time bigint DEFAULT 10,
+    points bigint DEFAULT 10,
+    category varchar(100) NOT NULL,
+    sub_category varchar(100),
+    tags text[],
+    requirements text[],
+    image_url text,
+    created_by bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_questions_question_id ON questions (question_id);
+CREATE INDEX IF NOT EXISTS idx_questions_category ON questions (category);
+CREATE INDEX IF NOT EXISTS idx_questions_deleted_at ON questions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS contests (
+    id bigserial PRIMARY KEY,
+    title varchar(255) NOT NULL,
+    description text,
+    start_time timestamptz NOT NULL,
+    end_time timestamptz NOT NULL,
+    freeze_minutes bigint DEFAULT 0,
+    time_penalty_per_minute bigint DEFAULT 0,
+    wrong_attempt_penalty bigint DEFAULT 0,
+    created_by bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_contests_start_time ON contests (start_time);
+CREATE INDEX IF NOT EXISTS idx_contests_deleted_at ON contests (deleted_at);
+
+CREATE TABLE IF NOT EXISTS contest_questions (
+    id bigserial PRIMARY KEY,
+    contest_id bigint NOT NULL,
+    question_id bigint NOT NULL,
+    position bigint NOT NULL,
+    points bigint NOT NULL,
+    CONSTRAINT fk_contests_questions FOREIGN KEY (contest_id) REFERENCES contests (id),
+    CONSTRAINT fk_contest_questions_question FOREIGN KEY (question_id) REFERENCES questions (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contest_question ON contest_questions (contest_id, question_id);
+
+CREATE TABLE IF NOT EXISTS contest_registrations (
+    id bigserial PRIMARY KEY,
+    contest_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_contest_registrations_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contest_registration ON contest_registrations (contest_id, user_id);
+
+CREATE TABLE IF NOT EXISTS submissions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    question_id bigint NOT NULL,
+    contest_id bigint,
+    room_id bigint,
+    answer text NOT NULL,
+    is_correct boolean DEFAULT false,
+    points bigint DEFAULT 0,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_submissions_user_id ON submissions (user_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_question_id ON submissions (question_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_contest_id ON submissions (contest_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_room_id ON submissions (room_id);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id bigserial PRIMARY KEY,
+    invite_code varchar(12) NOT NULL,
+    host_id bigint NOT NULL,
+    category varchar(100),
+    difficulty varchar(20),
+    question_count bigint NOT NULL,
+    seconds_per_question bigint NOT NULL,
+    max_participants bigint NOT NULL,
+    ranked boolean DEFAULT false,
+    challenged_id bigint,
+    status varchar(20) NOT NULL DEFAULT 'waiting',
+    started_at timestamptz,
+    finished_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_invite_code ON rooms (invite_code);
+CREATE INDEX IF NOT EXISTS idx_rooms_host_id ON rooms (host_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_challenged_id ON rooms (challenged_id);
+
+CREATE TABLE IF NOT EXISTS room_participants (
+    id bigserial PRIMARY KEY,
+    room_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    score bigint DEFAULT 0,
+    correct bigint DEFAULT 0,
+    rank bigint DEFAULT 0,
+    rating_change bigint DEFAULT 0,
+    created_at timestamptz,
+    CONSTRAINT fk_rooms_participants FOREIGN KEY (room_id) REFERENCES rooms (id),
+    CONSTRAINT fk_room_participants_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_participant ON room_participants (room_id, user_id);
+
+CREATE TABLE IF NOT EXISTS room_questions (
+    id bigserial PRIMARY KEY,
+    room_id bigint NOT NULL,
+    question_id bigint NOT NULL,
+    position bigint NOT NULL,
+    CONSTRAINT fk_rooms_questions FOREIGN KEY (room_id) REFERENCES rooms (id),
+    CONSTRAINT fk_room_questions_question FOREIGN KEY (question_id) REFERENCES questions (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_question ON room_questions (room_id, question_id);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    code varchar(50) NOT NULL,
+    earned_at timestamptz NOT NULL,
+    announced boolean DEFAULT false
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_achievement ON user_achievements (user_id, code);
+
+CREATE TABLE IF NOT EXISTS friendships (
+    id bigserial PRIMARY KEY,
+    requester_id bigint NOT NULL,
+    addressee_id bigint NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    accepted_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_friendships_requester FOREIGN KEY (requester_id) REFERENCES users (id),
+    CONSTRAINT fk_friendships_addressee FOREIGN KEY (addressee_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendship_pair ON friendships (requester_id, addressee_id);
+CREATE INDEX IF NOT EXISTS idx_friendships_addressee_id ON friendships (addressee_id);
+
+CREATE TABLE IF NOT EXISTS user_blocks (
+    id bigserial PRIMARY KEY,
+    blocker_id bigint NOT NULL,
+    blocked_id bigint NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_user_blocks_blocked FOREIGN KEY (blocked_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_block ON user_blocks (blocker_id, blocked_id);
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks (blocked_id);
+
+CREATE TABLE IF NOT EXISTS oauth_states (
+    id bigserial PRIMARY KEY,
+    state_hash varchar(64) NOT NULL,
+    binding_hash varchar(64) NOT NULL,
+    code_verifier varchar(128) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_states_state_hash ON oauth_states (state_hash);
+CREATE INDEX IF NOT EXISTS idx_oauth_states_expires_at ON oauth_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id bigserial PRIMARY KEY,
+    session_id varchar(36) NOT NULL,
+    user_id bigint NOT NULL,
+    refresh_token_hash varchar(64) NOT NULL,
+    user_agent varchar(512),
+    ip_address varchar(64),
+    last_used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sessions_session_id ON user_sessions (session_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sessions_refresh_token_hash ON user_sessions (refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_revoked_at ON user_sessions (revoked_at);
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    email text NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user_id ON email_verifications (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_verifications_token_hash ON email_verifications (token_hash);
+CREATE INDEX IF NOT EXISTS idx_email_verifications_expires_at ON email_verifications (expires_at);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    role varchar(50) NOT NULL,
+    granted_by bigint,
+    created_at timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role ON user_roles (user_id, role);
+
+CREATE TABLE IF NOT EXISTS role_changes (
+    id bigserial PRIMARY KEY,
+    actor_id bigint NOT NULL,
+    target_id bigint NOT NULL,
+    role varchar(50) NOT NULL,
+    action varchar(10) NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_role_changes_actor_id ON role_changes (actor_id);
+CREATE INDEX IF NOT EXISTS idx_role_changes_target_id ON role_changes (target_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id bigserial PRIMARY KEY,
+    actor_id bigint NOT NULL,
+    action varchar(50) NOT NULL,
+    target_type varchar(30) NOT NULL,
+    target_id varchar(64),
+    before jsonb,
+    after jsonb,
+    ip_address varchar(64),
+    request_id varchar(64),
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_target ON audit_logs (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,2 @@
+-- Backfilled roles are indistinguishable from granted ones once the user holds
+-- them, so rolling back leaves user_roles as it is
@@ -0,0 +1,5 @@
+-- Grant the admin role to users flagged with is_admin before roles existed
+
+INSERT INTO user_roles (user_id, role, created_at)
+    SELECT id, 'admin', NOW() FROM users WHERE is_admin AND deleted_at IS NULL
+    ON CONFLICT DO NOTHING;
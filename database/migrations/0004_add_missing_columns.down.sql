@@ -0,0 +1,2 @@
+-- The columns hold data written since they were added and, on databases 0001
+-- created, belong to the baseline, so rolling back leaves them in place
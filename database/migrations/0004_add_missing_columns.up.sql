@@ -0,0 +1,23 @@
+-- Databases created before 0001 already had users, and some had submissions and
+-- rooms from an earlier AutoMigrate, so 0001 skipped them. Add every column those
+-- tables gained since, with the same defaults. On databases 0001 created this is a no-op.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified boolean DEFAULT false,
+    ADD COLUMN IF NOT EXISTS rating bigint DEFAULT 1200,
+    ADD COLUMN IF NOT EXISTS suspended_at timestamptz,
+    ADD COLUMN IF NOT EXISTS suspension_reason varchar(500),
+    ADD COLUMN IF NOT EXISTS email_visibility varchar(10) DEFAULT 'private',
+    ADD COLUMN IF NOT EXISTS phone_visibility varchar(10) DEFAULT 'private',
+    ADD COLUMN IF NOT EXISTS country_visibility varchar(10) DEFAULT 'public',
+    ADD COLUMN IF NOT EXISTS bio_visibility varchar(10) DEFAULT 'public',
+    ADD COLUMN IF NOT EXISTS rating_visibility varchar(10) DEFAULT 'public',
+    ADD COLUMN IF NOT EXISTS achievements_visibility varchar(10) DEFAULT 'public',
+    ADD COLUMN IF NOT EXISTS stats_visibility varchar(10) DEFAULT 'public';
+CREATE INDEX IF NOT EXISTS idx_users_suspended_at ON users (suspended_at);
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS room_id bigint;
+CREATE INDEX IF NOT EXISTS idx_submissions_room_id ON submissions (room_id);
+
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS challenged_id bigint;
+CREATE INDEX IF NOT EXISTS idx_rooms_challenged_id ON rooms (challenged_id);
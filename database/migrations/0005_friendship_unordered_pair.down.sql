@@ -0,0 +1,4 @@
+-- Merged duplicate requests are not restored
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendship_pair ON friendships (requester_id, addressee_id);
+DROP INDEX IF EXISTS idx_friendships_requester_id;
+DROP INDEX IF EXISTS idx_friendship_unordered_pair;
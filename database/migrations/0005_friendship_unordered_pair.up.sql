@@ -0,0 +1,13 @@
+-- A friendship is one row per pair of users whichever way the request went, so the
+-- unique index is on the unordered pair. Requests already sent both ways are merged
+-- first, keeping the accepted row, or else the earlier one.
+
+DELETE FROM friendships f
+    USING friendships g
+    WHERE f.requester_id = g.addressee_id AND f.addressee_id = g.requester_id
+      AND ((g.status = 'accepted' AND f.status <> 'accepted') OR (g.status = f.status AND g.id < f.id));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendship_unordered_pair
+    ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX IF NOT EXISTS idx_friendships_requester_id ON friendships (requester_id);
+DROP INDEX IF EXISTS idx_friendship_pair;
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check probes one dependency. Critical checks make the service unready when they
+// fail; the others only mark it degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	// Run returns optional details to report, such as pool statistics, and an error when unhealthy
+	Run func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string                 `json:"status"` // "up" or "down"
+	Critical  bool                   `json:"critical"`
+	LatencyMs float64                `json:"latencyMs"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Report is the readiness of the service as a whole
+type Report struct {
+	Status     string            `json:"status"` // "ready", "degraded" or "unready"
+	Draining   bool              `json:"draining,omitempty"`
+	Components map[string]Result `json:"components"`
+}
+
+// Ready reports whether the service should receive traffic
+func (r Report) Ready() bool {
+	return r.Status != "unready"
+}
+
+// Checker runs the readiness checks
+type Checker struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewChecker creates a Checker that gives each check up to timeout
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// SetDraining makes the service report unready from now on, so orchestrators stop
+// routing new requests to it while it shuts down
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Run executes every check concurrently and summarizes the results
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: "ready", Draining: c.draining.Load(), Components: make(map[string]Result, len(c.checks))}
+	for i, check := range c.checks {
+		result := results[i]
+		report.Components[check.Name] = result
+		if result.Status == "up" {
+			continue
+		}
+		if check.Critical {
+			report.Status = "unready"
+		} else if report.Status == "ready" {
+			report.Status = "degraded"
+		}
+	}
+	if report.Draining {
+		report.Status = "unready"
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Run(ctx)
+	result := Result{
+		Status:    "up",
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,315 @@
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/tracing"
+)
+
+const (
+	identityToolkitURL = "https://identitytoolkit.googleapis.com/v1/"
+	secureTokenURL     = "https://securetoken.googleapis.com/v1/"
+)
+
+// requestTimeout bounds each call to Firebase, so a hung identity backend fails
+// the request instead of holding it open
+const requestTimeout = 10 * time.Second
+
+// FirebaseProvider implements IdentityProvider with the Firebase Admin SDK
+// and the Firebase Auth REST API
+type FirebaseProvider struct {
+	client     *auth.Client
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewFirebaseProvider creates a provider for the given Firebase app.
+// webAPIKey is the project's web API key, used for the REST sign-in endpoints.
+func NewFirebaseProvider(ctx context.Context, app *firebase.App, webAPIKey string) (*FirebaseProvider, error) {
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Auth client: %w", err)
+	}
+
+	return &FirebaseProvider{
+		client:     client,
+		apiKey:     webAPIKey,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// VerifyIDToken checks a Firebase ID token and that its account still exists, is enabled
+// and has not had its tokens revoked
+func (p *FirebaseProvider) VerifyIDToken(ctx context.Context, idToken string) (*Token, error) {
+	// Verification fetches Google's signing keys when the cached set expires, and looks up
+	// the account for the revocation check
+	ctx, span := tracing.Tracer.Start(ctx, "firebase.VerifyIDToken")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	token, err := p.client.VerifyIDTokenAndCheckRevoked(ctx, idToken)
+	if err != nil {
+		tracing.RecordError(span, err)
+		if auth.IsUserDisabled(err) {
+			return nil, fmt.Errorf("%w: %v", ErrUserDisabled, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &Token{UID: token.UID, Claims: token.Claims}, nil
+}
+
+// CreateUser creates a Firebase user
+func (p *FirebaseProvider) CreateUser(ctx context.Context, params UserToCreate) (*UserRecord, error) {
+	toCreate := (&auth.UserToCreate{}).
+		Email(params.Email).
+		EmailVerified(params.EmailVerified)
+	if params.Password != "" {
+		toCreate = toCreate.Password(params.Password)
+	}
+	if params.DisplayName != "" {
+		toCreate = toCreate.DisplayName(params.DisplayName)
+	}
+	if params.PhotoURL != "" {
+		toCreate = toCreate.PhotoURL(params.PhotoURL)
+	}
+
+	user, err := p.client.CreateUser(ctx, toCreate)
+	if err != nil {
+		if auth.IsEmailAlreadyExists(err) {
+			return nil, &RemoteError{Message: err.Error(), Err: ErrEmailExists}
+		}
+		return nil, err
+	}
+	return firebaseUserRecord(user), nil
+}
+
+// GetUser looks up a Firebase user by UID
+func (p *FirebaseProvider) GetUser(ctx context.Context, uid string) (*UserRecord, error) {
+	user, err := p.client.GetUser(ctx, uid)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return firebaseUserRecord(user), nil
+}
+
+// GetUserByEmail looks up a Firebase user by email
+func (p *FirebaseProvider) GetUserByEmail(ctx context.Context, email string) (*UserRecord, error) {
+	user, err := p.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return firebaseUserRecord(user), nil
+}
+
+// SignIn verifies an email and password with the Firebase Auth REST API
+func (p *FirebaseProvider) SignIn(ctx context.Context, email, password string) (*Session, error) {
+	var resp struct {
+		IDToken      string `json:"idToken"`
+		Email        string `json:"email"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresIn    string `json:"expiresIn"`
+		LocalID      string `json:"localId"`
+	}
+	err := p.post(ctx, "accounts:signInWithPassword", map[string]interface{}{
+		"email":             email,
+		"password":          password,
+		"returnSecureToken": true,
+	}, &resp, ErrInvalidCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresIn, _ := strconv.Atoi(resp.ExpiresIn)
+	return &Session{
+		UID:          resp.LocalID,
+		Email:        resp.Email,
+		IDToken:      resp.IDToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+// SignInWithCustomToken exchanges a Firebase custom token for an ID token
+func (p *FirebaseProvider) SignInWithCustomToken(ctx context.Context, customToken string) (*Session, error) {
+	var resp struct {
+		IDToken      string `json:"idToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresIn    string `json:"expiresIn"`
+	}
+	err := p.post(ctx, "accounts:signInWithCustomToken", map[string]interface{}{
+		"token":             customToken,
+		"returnSecureToken": true,
+	}, &resp, ErrInvalidToken)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresIn, _ := strconv.Atoi(resp.ExpiresIn)
+	return &Session{
+		IDToken:      resp.IDToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+// SendPasswordReset asks Firebase to email a password reset link
+func (p *FirebaseProvider) SendPasswordReset(ctx context.Context, email string) error {
+	return p.post(ctx, "accounts:sendOobCode", map[string]interface{}{
+		"email":       email,
+		"requestType": "PASSWORD_RESET",
+	}, nil, ErrUserNotFound)
+}
+
+// CustomToken mints a Firebase custom token
+func (p *FirebaseProvider) CustomToken(ctx context.Context, uid string) (string, error) {
+	return p.client.CustomToken(ctx, uid)
+}
+
+// RefreshIDToken exchanges a Firebase refresh token using the Secure Token API
+func (p *FirebaseProvider) RefreshIDToken(ctx context.Context, refreshToken string) (*Session, error) {
+	var resp struct {
+		IDToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    string `json:"expires_in"`
+		UserID       string `json:"user_id"`
+	}
+	err := p.postURL(ctx, secureTokenURL+"token", map[string]interface{}{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}, &resp, ErrInvalidToken)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresIn, _ := strconv.Atoi(resp.ExpiresIn)
+	return &Session{
+		UID:          resp.UserID,
+		IDToken:      resp.IDToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+// RevokeRefreshTokens revokes all of a Firebase user's refresh tokens
+func (p *FirebaseProvider) RevokeRefreshTokens(ctx context.Context, uid string) error {
+	err := p.client.RevokeRefreshTokens(ctx, uid)
+	if auth.IsUserNotFound(err) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// SetEmailVerified updates the Firebase user's emailVerified flag
+func (p *FirebaseProvider) SetEmailVerified(ctx context.Context, uid string, verified bool) error {
+	_, err := p.client.UpdateUser(ctx, uid, (&auth.UserToUpdate{}).EmailVerified(verified))
+	if auth.IsUserNotFound(err) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// SetDisabled disables or re-enables the Firebase user
+func (p *FirebaseProvider) SetDisabled(ctx context.Context, uid string, disabled bool) error {
+	_, err := p.client.UpdateUser(ctx, uid, (&auth.UserToUpdate{}).Disabled(disabled))
+	if auth.IsUserNotFound(err) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// DeleteUser deletes the Firebase user
+func (p *FirebaseProvider) DeleteUser(ctx context.Context, uid string) error {
+	err := p.client.DeleteUser(ctx, uid)
+	if auth.IsUserNotFound(err) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// SetCustomClaims replaces the Firebase user's custom claims
+func (p *FirebaseProvider) SetCustomClaims(ctx context.Context, uid string, claims map[string]interface{}) error {
+	err := p.client.SetCustomUserClaims(ctx, uid, claims)
+	if auth.IsUserNotFound(err) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// post calls a Firebase Auth REST endpoint. Error responses are returned as a
+// RemoteError carrying Firebase's message and classified as failure.
+func (p *FirebaseProvider) post(ctx context.Context, endpoint string, body interface{}, out interface{}, failure error) error {
+	return p.postURL(ctx, identityToolkitURL+endpoint, body, out, failure)
+}
+
+func (p *FirebaseProvider) postURL(ctx context.Context, url string, body interface{}, out interface{}, failure error) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error processing request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"?key="+p.apiKey, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		// The error quotes the request URL, which carries the API key
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = url
+		}
+		return fmt.Errorf("error communicating with authentication service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || errorResponse.Error.Message == "" {
+			return &RemoteError{Message: failure.Error(), Err: failure}
+		}
+		return &RemoteError{Message: errorResponse.Error.Message, Err: failure}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error processing response: %w", err)
+	}
+	return nil
+}
+
+func firebaseUserRecord(user *auth.UserRecord) *UserRecord {
+	return &UserRecord{
+		UID:           user.UID,
+		Email:         user.Email,
+		DisplayName:   user.DisplayName,
+		PhotoURL:      user.PhotoURL,
+		EmailVerified: user.EmailVerified,
+		Disabled:      user.Disabled,
+		CustomClaims:  user.CustomClaims,
+	}
+}
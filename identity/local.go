@@ -0,0 +1,524 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	localIssuer      = "thinkbattleground-local"
+	localIDTokenTTL  = time.Hour
+	localCustomTTL   = 5 * time.Minute
+	tokenUseID       = "id"
+	tokenUseCustom   = "custom"
+	minLocalKeyBytes = 32
+	localRefreshTTL  = 30 * 24 * time.Hour
+)
+
+// LocalProvider is a self-contained IdentityProvider that signs HS256 JWTs with a shared secret.
+// It is meant for local development and integration tests; accounts are kept in memory and,
+// when a store path is given, persisted to a JSON file so they survive restarts.
+// Refresh tokens are only held in memory, so clients must sign in again after a restart.
+type LocalProvider struct {
+	mu            sync.RWMutex
+	secret        []byte
+	storePath     string
+	users         map[string]*localUser // keyed by UID
+	resets        []string
+	refreshTokens map[string]localRefreshToken // keyed by token
+}
+
+type localRefreshToken struct {
+	uid       string
+	expiresAt time.Time
+}
+
+type localUser struct {
+	UID           string `json:"uid"`
+	Email         string `json:"email"`
+	PasswordHash  string `json:"passwordHash,omitempty"`
+	DisplayName   string `json:"displayName,omitempty"`
+	PhotoURL      string `json:"photoUrl,omitempty"`
+	EmailVerified bool   `json:"emailVerified"`
+	Disabled      bool   `json:"disabled"`
+	// TokensValidAfter is the Unix time of the last RevokeRefreshTokens; ID tokens
+	// issued before it are rejected
+	TokensValidAfter int64 `json:"tokensValidAfter,omitempty"`
+
+	CustomClaims map[string]interface{} `json:"customClaims,omitempty"`
+}
+
+// NewLocalProvider creates a local provider signing tokens with secret.
+// storePath may be empty to keep accounts in memory only.
+func NewLocalProvider(secret []byte, storePath string) (*LocalProvider, error) {
+	if len(secret) < minLocalKeyBytes {
+		return nil, fmt.Errorf("local identity provider secret must be at least %d bytes", minLocalKeyBytes)
+	}
+
+	p := &LocalProvider{
+		secret:    secret,
+		storePath: storePath,
+		users:     make(map[string]*localUser),
+
+		refreshTokens: make(map[string]localRefreshToken),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// VerifyIDToken checks the signature, issuer and expiry of a local ID token, and that
+// its account still exists, is enabled and has not had its tokens revoked since
+func (p *LocalProvider) VerifyIDToken(ctx context.Context, idToken string) (*Token, error) {
+	claims, err := p.parse(idToken, tokenUseID)
+	if err != nil {
+		return nil, err
+	}
+	uid, _ := claims["sub"].(string)
+
+	p.mu.RLock()
+	user := p.users[uid]
+	p.mu.RUnlock()
+	if user == nil {
+		return nil, fmt.Errorf("%w: account does not exist", ErrInvalidToken)
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	if issuedAt, _ := claims["iat"].(float64); int64(issuedAt) < user.TokensValidAfter {
+		return nil, fmt.Errorf("%w: token has been revoked", ErrInvalidToken)
+	}
+
+	return &Token{UID: uid, Claims: claims}, nil
+}
+
+// CreateUser adds an account, hashing its password if one is given
+func (p *LocalProvider) CreateUser(ctx context.Context, params UserToCreate) (*UserRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+	if p.findByEmail(email) != nil {
+		return nil, &RemoteError{Message: "EMAIL_EXISTS", Err: ErrEmailExists}
+	}
+
+	user := &localUser{
+		UID:           strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Email:         email,
+		DisplayName:   params.DisplayName,
+		PhotoURL:      params.PhotoURL,
+		EmailVerified: params.EmailVerified,
+	}
+	if params.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		user.PasswordHash = string(hash)
+	}
+
+	p.users[user.UID] = user
+	if err := p.save(); err != nil {
+		delete(p.users, user.UID)
+		return nil, err
+	}
+	return user.record(), nil
+}
+
+// GetUser looks up an account by UID
+func (p *LocalProvider) GetUser(ctx context.Context, uid string) (*UserRecord, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user.record(), nil
+}
+
+// GetUserByEmail looks up an account by email
+func (p *LocalProvider) GetUserByEmail(ctx context.Context, email string) (*UserRecord, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	user := p.findByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user.record(), nil
+}
+
+// SignIn checks an email and password and issues an ID token
+func (p *LocalProvider) SignIn(ctx context.Context, email, password string) (*Session, error) {
+	p.mu.RLock()
+	user := p.findByEmail(strings.ToLower(strings.TrimSpace(email)))
+	p.mu.RUnlock()
+
+	if user == nil || user.PasswordHash == "" ||
+		bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, &RemoteError{Message: "INVALID_LOGIN_CREDENTIALS", Err: ErrInvalidCredentials}
+	}
+	if user.Disabled {
+		return nil, &RemoteError{Message: "USER_DISABLED", Err: ErrInvalidCredentials}
+	}
+
+	return p.session(user)
+}
+
+// SignInWithCustomToken exchanges a token from CustomToken for an ID token
+func (p *LocalProvider) SignInWithCustomToken(ctx context.Context, customToken string) (*Session, error) {
+	claims, err := p.parse(customToken, tokenUseCustom)
+	if err != nil {
+		return nil, err
+	}
+	uid, _ := claims["sub"].(string)
+
+	p.mu.RLock()
+	user := p.users[uid]
+	p.mu.RUnlock()
+	if user == nil {
+		return nil, &RemoteError{Message: "USER_NOT_FOUND", Err: ErrInvalidToken}
+	}
+	if user.Disabled {
+		return nil, &RemoteError{Message: "USER_DISABLED", Err: ErrInvalidToken}
+	}
+
+	return p.session(user)
+}
+
+// SendPasswordReset records a reset request instead of sending an email
+func (p *LocalProvider) SendPasswordReset(ctx context.Context, email string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if p.findByEmail(email) == nil {
+		return &RemoteError{Message: "EMAIL_NOT_FOUND", Err: ErrUserNotFound}
+	}
+	p.resets = append(p.resets, email)
+	return nil
+}
+
+// PasswordResets returns the emails for which a password reset was requested
+func (p *LocalProvider) PasswordResets() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.resets...)
+}
+
+// CustomToken mints a short-lived token for SignInWithCustomToken
+func (p *LocalProvider) CustomToken(ctx context.Context, uid string) (string, error) {
+	now := time.Now()
+	return p.sign(jwt.MapClaims{
+		"iss":       localIssuer,
+		"sub":       uid,
+		"iat":       now.Unix(),
+		"exp":       now.Add(localCustomTTL).Unix(),
+		"token_use": tokenUseCustom,
+	})
+}
+
+// IssueIDToken mints an ID token for an existing account without a password,
+// which is convenient for development tools and tests
+func (p *LocalProvider) IssueIDToken(uid string) (string, error) {
+	p.mu.RLock()
+	user := p.users[uid]
+	p.mu.RUnlock()
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+
+	session, err := p.session(user)
+	if err != nil {
+		return "", err
+	}
+	return session.IDToken, nil
+}
+
+// RefreshIDToken exchanges a refresh token for a new ID token. Refresh tokens are
+// single-use: the returned session carries a replacement.
+func (p *LocalProvider) RefreshIDToken(ctx context.Context, refreshToken string) (*Session, error) {
+	p.mu.Lock()
+	stored, ok := p.refreshTokens[refreshToken]
+	delete(p.refreshTokens, refreshToken)
+	user := p.users[stored.uid]
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(stored.expiresAt) {
+		return nil, &RemoteError{Message: "INVALID_REFRESH_TOKEN", Err: ErrInvalidToken}
+	}
+	if user == nil {
+		return nil, &RemoteError{Message: "USER_NOT_FOUND", Err: ErrInvalidToken}
+	}
+	if user.Disabled {
+		return nil, &RemoteError{Message: "USER_DISABLED", Err: ErrInvalidToken}
+	}
+
+	return p.session(user)
+}
+
+// RevokeRefreshTokens drops every outstanding refresh token for the account and
+// rejects the ID tokens it was issued until now
+func (p *LocalProvider) RevokeRefreshTokens(ctx context.Context, uid string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return ErrUserNotFound
+	}
+	updated := *user
+	updated.TokensValidAfter = time.Now().Unix()
+	p.users[uid] = &updated
+	if err := p.save(); err != nil {
+		p.users[uid] = user
+		return err
+	}
+	for token, stored := range p.refreshTokens {
+		if stored.uid == uid {
+			delete(p.refreshTokens, token)
+		}
+	}
+	return nil
+}
+
+// SetEmailVerified updates the account's emailVerified flag
+func (p *LocalProvider) SetEmailVerified(ctx context.Context, uid string, verified bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return ErrUserNotFound
+	}
+	// Replace rather than mutate the entry, which readers may hold outside the lock
+	updated := *user
+	updated.EmailVerified = verified
+	p.users[uid] = &updated
+	if err := p.save(); err != nil {
+		p.users[uid] = user
+		return err
+	}
+	return nil
+}
+
+// SetDisabled disables or re-enables the account
+func (p *LocalProvider) SetDisabled(ctx context.Context, uid string, disabled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return ErrUserNotFound
+	}
+	updated := *user
+	updated.Disabled = disabled
+	p.users[uid] = &updated
+	if err := p.save(); err != nil {
+		p.users[uid] = user
+		return err
+	}
+	return nil
+}
+
+// DeleteUser removes the account and its refresh tokens
+func (p *LocalProvider) DeleteUser(ctx context.Context, uid string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return ErrUserNotFound
+	}
+	delete(p.users, uid)
+	if err := p.save(); err != nil {
+		p.users[uid] = user
+		return err
+	}
+	for token, stored := range p.refreshTokens {
+		if stored.uid == uid {
+			delete(p.refreshTokens, token)
+		}
+	}
+	return nil
+}
+
+// SetCustomClaims replaces the account's custom claims, which are added to ID tokens it issues
+func (p *LocalProvider) SetCustomClaims(ctx context.Context, uid string, claims map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := p.users[uid]
+	if user == nil {
+		return ErrUserNotFound
+	}
+	updated := *user
+	updated.CustomClaims = claims
+	p.users[uid] = &updated
+	if err := p.save(); err != nil {
+		p.users[uid] = user
+		return err
+	}
+	return nil
+}
+
+func (p *LocalProvider) session(user *localUser) (*Session, error) {
+	now := time.Now()
+	standard := jwt.MapClaims{
+		"iss":            localIssuer,
+		"aud":            localIssuer,
+		"sub":            user.UID,
+		"user_id":        user.UID,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.DisplayName,
+		"picture":        user.PhotoURL,
+		"auth_time":      now.Unix(),
+		"iat":            now.Unix(),
+		"exp":            now.Add(localIDTokenTTL).Unix(),
+		"token_use":      tokenUseID,
+	}
+	claims := jwt.MapClaims{}
+	for name, value := range user.CustomClaims {
+		claims[name] = value
+	}
+	// Standard claims are copied last so custom claims cannot override them
+	for name, value := range standard {
+		claims[name] = value
+	}
+	idToken, err := p.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.refreshTokens[refreshToken] = localRefreshToken{uid: user.UID, expiresAt: now.Add(localRefreshTTL)}
+	p.mu.Unlock()
+
+	return &Session{
+		UID:          user.UID,
+		Email:        user.Email,
+		IDToken:      idToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(localIDTokenTTL.Seconds()),
+	}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (p *LocalProvider) sign(claims jwt.MapClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.secret)
+}
+
+// parse verifies a token's signature and standard claims and checks that it was issued for use
+func (p *LocalProvider) parse(tokenString, use string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return p.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyIssuer(localIssuer, true) || claims["token_use"] != use {
+		return nil, ErrInvalidToken
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (p *LocalProvider) findByEmail(email string) *localUser {
+	for _, user := range p.users {
+		if user.Email == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// load reads persisted accounts, if a store is configured and exists
+func (p *LocalProvider) load() error {
+	if p.storePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.storePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read local identity store: %w", err)
+	}
+
+	var users []*localUser
+	if err := json.Unmarshal(data, &users); err != nil {
+		return fmt.Errorf("failed to parse local identity store: %w", err)
+	}
+	for _, user := range users {
+		p.users[user.UID] = user
+	}
+	return nil
+}
+
+// save persists accounts to the store, if one is configured. Callers must hold the write lock.
+func (p *LocalProvider) save() error {
+	if p.storePath == "" {
+		return nil
+	}
+
+	users := make([]*localUser, 0, len(p.users))
+	for _, user := range p.users {
+		users = append(users, user)
+	}
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode local identity store: %w", err)
+	}
+
+	tmp := p.storePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write local identity store: %w", err)
+	}
+	return os.Rename(tmp, p.storePath)
+}
+
+func (u *localUser) record() *UserRecord {
+	return &UserRecord{
+		UID:           u.UID,
+		Email:         u.Email,
+		DisplayName:   u.DisplayName,
+		PhotoURL:      u.PhotoURL,
+		EmailVerified: u.EmailVerified,
+		Disabled:      u.Disabled,
+		CustomClaims:  u.CustomClaims,
+	}
+}
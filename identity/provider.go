@@ -0,0 +1,100 @@
+package identity
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailExists        = errors.New("email already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrUserDisabled       = errors.New("user account is disabled")
+)
+
+// IdentityProvider verifies tokens and manages user accounts for an authentication backend
+type IdentityProvider interface {
+	// VerifyIDToken checks an ID token presented by a client and returns its claims. Tokens
+	// issued before RevokeRefreshTokens, or for an account that no longer exists, fail with
+	// ErrInvalidToken; tokens for a disabled account fail with ErrUserDisabled.
+	VerifyIDToken(ctx context.Context, idToken string) (*Token, error)
+	// CreateUser registers a new account
+	CreateUser(ctx context.Context, params UserToCreate) (*UserRecord, error)
+	// GetUser looks up an account by UID, returning ErrUserNotFound if there is none
+	GetUser(ctx context.Context, uid string) (*UserRecord, error)
+	// GetUserByEmail looks up an account, returning ErrUserNotFound if there is none
+	GetUserByEmail(ctx context.Context, email string) (*UserRecord, error)
+	// SignIn exchanges an email and password for an ID token
+	SignIn(ctx context.Context, email, password string) (*Session, error)
+	// SignInWithCustomToken exchanges a token from CustomToken for an ID token
+	SignInWithCustomToken(ctx context.Context, customToken string) (*Session, error)
+	// SendPasswordReset starts the password reset flow for an account
+	SendPasswordReset(ctx context.Context, email string) error
+	// CustomToken mints a token that lets the server sign a user in without their password
+	CustomToken(ctx context.Context, uid string) (string, error)
+	// RefreshIDToken exchanges a refresh token for a new ID token
+	RefreshIDToken(ctx context.Context, refreshToken string) (*Session, error)
+	// RevokeRefreshTokens invalidates every refresh token issued to the user, returning
+	// ErrUserNotFound if there is no account
+	RevokeRefreshTokens(ctx context.Context, uid string) error
+	// SetEmailVerified marks the account's email address as verified or not
+	SetEmailVerified(ctx context.Context, uid string, verified bool) error
+	// SetDisabled disables or re-enables an account. Disabled accounts cannot sign in or refresh tokens.
+	SetDisabled(ctx context.Context, uid string, disabled bool) error
+	// DeleteUser removes an account, returning ErrUserNotFound if there is none
+	DeleteUser(ctx context.Context, uid string) error
+	// SetCustomClaims replaces the account's custom claims. They appear in ID tokens
+	// issued afterwards, so clients see changes on their next token refresh.
+	SetCustomClaims(ctx context.Context, uid string, claims map[string]interface{}) error
+}
+
+// Token is a verified ID token
+type Token struct {
+	UID    string
+	Claims map[string]interface{}
+}
+
+// UserRecord describes an account held by the identity provider
+type UserRecord struct {
+	UID           string
+	Email         string
+	DisplayName   string
+	PhotoURL      string
+	EmailVerified bool
+	Disabled      bool
+	CustomClaims  map[string]interface{}
+}
+
+// UserToCreate holds the fields for a new account. Password may be empty for federated sign-ins.
+type UserToCreate struct {
+	Email         string
+	Password      string
+	DisplayName   string
+	PhotoURL      string
+	EmailVerified bool
+}
+
+// Session is the result of a successful sign-in
+type Session struct {
+	UID          string
+	Email        string
+	IDToken      string
+	RefreshToken string
+	ExpiresIn    int // seconds until IDToken expires
+}
+
+// RemoteError carries an error message returned by the provider's backend,
+// classified by one of the package's sentinel errors
+type RemoteError struct {
+	Message string
+	Err     error
+}
+
+func (e *RemoteError) Error() string {
+	return e.Message
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Manager runs background workers and releases resources in order when the server stops
+type Manager struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu    sync.Mutex
+	hooks []hook
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a Manager with no workers or hooks
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in the background. Its context is cancelled at shutdown and fn must
+// return soon after; Shutdown waits for it before running any hook.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.ctx)
+		slog.Info("Worker stopped", "worker", name)
+	}()
+}
+
+// OnShutdown registers fn to run at shutdown, after every worker has stopped.
+// Hooks run in the order they were registered, so register dependents first.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown stops the workers, then runs the hooks. It gives up waiting for workers
+// when ctx ends, but still runs every hook, and returns all the errors together.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.cancel()
+
+	var errs []error
+	stopped := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("workers did not stop: %w", ctx.Err()))
+	}
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.mu.Unlock()
+	for _, h := range hooks {
+		if err := h.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		slog.Info("Shut down", "component", h.name)
+	}
+	return errors.Join(errs...)
+}
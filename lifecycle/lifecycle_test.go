@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownStopsWorkersBeforeHooks(t *testing.T) {
+	m := New()
+	var order []string
+	stopped := make(chan struct{})
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	m.OnShutdown("hook", func(ctx context.Context) error {
+		select {
+		case <-stopped:
+			order = append(order, "hook after worker")
+		default:
+			order = append(order, "hook before worker")
+		}
+		return nil
+	})
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if len(order) != 1 || order[0] != "hook after worker" {
+		t.Errorf("expected the hook to run after the worker stopped, got %v", order)
+	}
+}
+
+func TestShutdownGivesUpOnStuckWorkers(t *testing.T) {
+	m := New()
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+	ran := false
+	m.OnShutdown("hook", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline error, got %v", err)
+	}
+	if !ran {
+		t.Error("expected hooks to run even though a worker did not stop")
+	}
+}
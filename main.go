@@ -1,44 +1,317 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/database"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/health"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/lifecycle"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/logging"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/routes"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/secrets"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/tracing"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// cleanupInterval is how often expired OAuth states and old revoked sessions are deleted
+	cleanupInterval = time.Hour
+	// revokedSessionRetention keeps revoked sessions visible in data exports for a while
+	revokedSessionRetention = 30 * 24 * time.Hour
+)
+
 func main() {
-	// Load environment variables
-	if err := config.LoadEnv(); err != nil {
-		log.Fatal("Failed to load .env file")
+	// Load configuration from flags, environment variables and the optional .env file
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Current = cfg
+
+	// Log structured entries with secret values masked; the standard log package
+	// goes through the same handler
+	redactor := secrets.NewRedactor(cfg.Secrets()...)
+	logging.Setup(redactor.Writer(os.Stderr), cfg.LogLevel, cfg.LogFormat)
+	gin.DefaultWriter = redactor.Writer(os.Stdout)
+	gin.DefaultErrorWriter = redactor.Writer(os.Stderr)
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			migrate(cfg, args[1:])
+		case "reconcile-roles":
+			reconcileRoles(cfg, args[1:])
+		default:
+			log.Fatalf("Unknown command %q (available: migrate, reconcile-roles)", args[0])
+		}
+		return
+	}
+
+	// Refuse to serve without the keys that requests depend on
+	if err := cfg.CheckSecrets(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Install the trace exporter before any client is created, so every span is exported
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.OTLPEndpoint, cfg.Tracing.SampleRatio)
+	if err != nil {
+		log.Fatal("Failed to initialize tracing: ", err)
 	}
 
-	// Initialize Gemini
-	if err := config.InitializeGemini(); err != nil {
+	// Initialize Gemini; without a key the server runs with question generation disabled
+	if cfg.GeminiAPIKey == "" {
+		slog.Warn("GEMINI_API_KEY is not set, question generation is disabled")
+	} else if err := config.InitializeGemini(cfg.GeminiAPIKey); err != nil {
 		log.Fatal("Failed to initialize Gemini: ", err)
 	}
 
 	// Initialize database
-	if err := database.InitDB(); err != nil {
+	if err := database.InitDB(cfg.Database); err != nil {
 		log.Fatal("Failed to initialize database: ", err)
 	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		log.Fatal("Failed to access database pool: ", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		log.Fatal("Failed to register database metrics: ", err)
+	}
 
 	// Initialize Gin router
-	router := gin.Default()
+	// Logging and recovery middleware are added by InitializeRoutes
+	router := gin.New()
+
+	// Initialize identity provider (Firebase by default)
+	provider, err := config.InitializeIdentityProvider(cfg.Identity)
+	if err != nil {
+		log.Fatal("Error initializing identity provider: ", err)
+	}
 
-	// Initialize Firebase App
-	firebaseApp := config.InitializeFirebase()
-	if firebaseApp == nil {
-		log.Fatal("Error initializing Firebase")
+	// Initialize email sender
+	sender, err := config.InitializeMailer(cfg.Mail)
+	if err != nil {
+		log.Fatal("Error initializing email sender: ", err)
 	}
 
+	// Readiness checks; question generation is optional, so Gemini only degrades readiness
+	generator := config.GeminiGenerator{}
+	checker := health.NewChecker(2*time.Second,
+		health.Check{Name: "database", Critical: true, Run: database.Ping},
+		health.Check{Name: "identity", Critical: true, Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"provider": cfg.Identity.Provider}, config.CheckIdentity(cfg.Identity)
+		}},
+		health.Check{Name: "generator", Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, generator.Ready()
+		}},
+	)
+
 	// Initialize routes
-	routes.InitializeRoutes(router, firebaseApp)
+	store := repository.NewPostgresStore(database.DB)
+	routes.InitializeRoutes(router, provider, sender, store, generator, checker)
+
+	// After requests drain, stop the cleanup workers and the metrics listener, release the
+	// Gemini client, close the database, then flush the spans recorded during shutdown
+	lc := lifecycle.New()
+	startCleanup(lc, "OAuth state cleanup", func(ctx context.Context) (int64, error) {
+		return store.OAuthStates().DeleteExpired(ctx, time.Now())
+	})
+	startCleanup(lc, "session cleanup", func(ctx context.Context) (int64, error) {
+		return store.Sessions().DeleteRevoked(ctx, time.Now().Add(-revokedSessionRetention))
+	})
+	if cfg.Server.MetricsAddr != "" {
+		metricsServer, err := listenMetrics(cfg.Server.MetricsAddr)
+		if err != nil {
+			log.Fatal("Failed to start metrics listener: ", err)
+		}
+		lc.OnShutdown("metrics listener", metricsServer.Shutdown)
+	}
+	lc.OnShutdown("Gemini client", func(ctx context.Context) error {
+		config.CloseGemini()
+		return nil
+	})
+	lc.OnShutdown("database pool", func(ctx context.Context) error {
+		return database.Close()
+	})
+	lc.OnShutdown("tracing", shutdownTracing)
 
 	// Start server
-	if err := router.Run(":8080"); err != nil {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	serve(server, checker, lc, cfg.Server.ShutdownTimeout)
+}
+
+// serve runs the server until SIGINT or SIGTERM, then reports unready, stops accepting
+// connections, waits up to timeout for in-flight requests and shuts down everything in lc
+func serve(server *http.Server, checker *health.Checker, lc *lifecycle.Manager, timeout time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	slog.Info("Listening", "addr", server.Addr)
+
+	select {
+	case err := <-errs:
 		log.Fatal("Error starting server: ", err)
+	case <-ctx.Done():
+	}
+	// Restore the default handling so a second signal exits immediately
+	stop()
+	checker.SetDraining()
+	slog.Info("Shutting down, waiting for in-flight requests", "timeout", timeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Requests still running at the deadline, closing their connections", "error", err)
+		server.Close()
+	}
+	if err := lc.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Shutdown incomplete", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// startCleanup runs cleanup as a worker in lc, at startup and then every cleanupInterval
+// until shutdown, logging how many rows each run deleted
+func startCleanup(lc *lifecycle.Manager, name string, cleanup func(ctx context.Context) (int64, error)) {
+	lc.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			deleted, err := cleanup(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Cleanup failed", "worker", name, "error", err)
+			} else if deleted > 0 {
+				slog.InfoContext(ctx, "Cleaned up", "worker", name, "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// listenMetrics serves /metrics on addr, apart from the public API. The address is bound
+// before returning so a port in use fails startup.
+func listenMetrics(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics listener stopped", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "addr", listener.Addr().String())
+	return server, nil
+}
+
+// migrate applies, rolls back or reports database migrations:
+//
+//	migrate up          apply every pending migration
+//	migrate down [n]    roll back the last n migrations (default 1)
+//	migrate status      list migrations and when they were applied
+func migrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate up | down [n] | status")
+	}
+
+	if err := database.Connect(cfg.Database); err != nil {
+		log.Fatal("Failed to initialize database: ", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(database.DB)
+		if err != nil {
+			log.Fatal("Failed to apply migrations: ", err)
+		}
+		log.Printf("Applied %d migrations", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := database.MigrateDown(database.DB, steps)
+		if err != nil {
+			log.Fatal("Failed to roll back migrations: ", err)
+		}
+		log.Printf("Rolled back %d migrations", len(rolledBack))
+	case "status":
+		statuses, err := database.MigrationStatuses(database.DB)
+		if err != nil {
+			log.Fatal("Failed to read migration status: ", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Unknown {
+				state += " (not in this build)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatalf("Unknown migrate command %q (available: up, down, status)", args[0])
+	}
+}
+
+// reconcileRoles rewrites identity provider claims that have drifted from the roles in the database
+func reconcileRoles(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("reconcile-roles", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report drift without changing any claims")
+	flags.Parse(args)
+
+	if err := database.InitDB(cfg.Database); err != nil {
+		log.Fatal("Failed to initialize database: ", err)
+	}
+	provider, err := config.InitializeIdentityProvider(cfg.Identity)
+	if err != nil {
+		log.Fatal("Error initializing identity provider: ", err)
+	}
+
+	result, err := rbac.Reconcile(context.Background(), database.DB, provider, *dryRun)
+	if err != nil {
+		log.Fatal("Failed to reconcile roles: ", err)
 	}
+	log.Printf("Checked %d users: %d with drifted claims, %d without an identity account (dry run: %t)",
+		result.Checked, result.Updated, result.Missing, *dryRun)
 }
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric the service exports. A dedicated registry keeps
+// metrics registered by libraries out of /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	GeminiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gemini_requests_total",
+		Help: "Question generation calls to Gemini, by outcome (ok, error or invalid_response).",
+	}, []string{"outcome"})
+
+	GeminiDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gemini_request_duration_seconds",
+		Help:    "Time taken by Gemini to generate a question.",
+		Buckets: []float64{0.5, 1, 2, 4, 8, 15, 30, 60},
+	})
+
+	GeminiTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gemini_tokens_total",
+		Help: "Tokens used by Gemini question generation, by type (prompt or output).",
+	}, []string{"type"})
+
+	Signups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "signups_total",
+		Help: "Accounts created, by method (password, google, or provisioned for an account made outside the API).",
+	}, []string{"method"})
+
+	QuestionsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "questions_created_total",
+		Help: "Questions saved, by source (gemini or manual).",
+	}, []string{"source"})
+
+	Submissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "submissions_total",
+		Help: "Answers submitted, by kind (contest or room).",
+	}, []string{"kind"})
+
+	CorrectAnswers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "correct_answers_total",
+		Help: "Submitted answers that were correct, by kind (contest or room).",
+	}, []string{"kind"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration,
+		GeminiRequests, GeminiDuration, GeminiTokens,
+		Signups, QuestionsCreated, Submissions, CorrectAnswers,
+	)
+}
+
+// RegisterDBStats exports the connection pool statistics of db
+func RegisterDBStats(db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
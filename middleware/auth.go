@@ -1,14 +1,19 @@
 package middleware
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 
-	"firebase.google.com/go/v4/auth"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(auth *auth.Client) gin.HandlerFunc {
+func AuthMiddleware(provider identity.IdentityProvider, users repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		idToken := strings.Replace(authHeader, "Bearer ", "", 1)
@@ -21,8 +26,14 @@ func AuthMiddleware(auth *auth.Client) gin.HandlerFunc {
 			return
 		}
 
-		// Verify the ID token
-		token, err := auth.VerifyIDToken(c, idToken)
+		// Verify the ID token. The provider also rejects tokens revoked since they were issued
+		// and tokens of accounts that were deleted or disabled, so none of those are provisioned.
+		token, err := provider.VerifyIDToken(c.Request.Context(), idToken)
+		if errors.Is(err, identity.ErrUserDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid token",
@@ -31,8 +42,41 @@ func AuthMiddleware(auth *auth.Client) gin.HandlerFunc {
 			return
 		}
 
-		// Add the user ID to the context
+		// Users who signed up outside this API (e.g. directly with Firebase) get a database row
+		// on their first request
+		user, created, err := users.Provision(c.Request.Context(), token.UID, token.Claims)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrAccountDeleted):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Account deleted"})
+			case errors.Is(err, repository.ErrCannotProvision):
+				c.JSON(http.StatusForbidden, gin.H{"error": "Account has no email address"})
+			default:
+				slog.ErrorContext(c.Request.Context(), "Failed to provision user", "uid", token.UID, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+			}
+			c.Abort()
+			return
+		}
+
+		if created {
+			metrics.Signups.WithLabelValues("provisioned").Inc()
+		}
+
+		// Tokens stay valid until they expire, so suspension is checked on every request
+		if user.SuspendedAt != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+			c.Abort()
+			return
+		}
+
+		// Add the user ID and the user to the context
 		c.Set("userId", token.UID)
+		c.Set("user", user)
+		// Roles mirrored into the token spare RequirePermission a database lookup
+		if roles, ok := rbac.RolesFromClaims(token.Claims); ok {
+			c.Set("roles", roles)
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission allows the request only when the user's roles grant every listed
+// permission. It must run after AuthMiddleware. Roles come from the token's claims when
+// present and from the user repository otherwise. A stale claim cannot outlive a role
+// removal: removing a role revokes the user's tokens, which AuthMiddleware then rejects.
+func RequirePermission(users repository.UserRepository, permissions ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get the user from the context (set by AuthMiddleware)
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		var roles []rbac.Role
+		if value, ok := c.Get("roles"); ok {
+			roles = value.([]rbac.Role)
+		} else {
+			// Fall back to the stored roles for tokens issued before roles were synced
+			var err error
+			roles, err = users.Roles(c.Request.Context(), user.(models.User).ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load roles"})
+				c.Abort()
+				return
+			}
+			c.Set("roles", roles)
+		}
+
+		for _, permission := range permissions {
+			if !rbac.Has(roles, permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Missing permission " + string(permission)})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
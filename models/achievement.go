@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAchievement records an achievement earned by a user.
+// Achievement definitions live in the achievements package and are referenced by Code.
+type UserAchievement struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_achievement;not null"`
+	Code      string    `gorm:"size:50;uniqueIndex:idx_user_achievement;not null"`
+	EarnedAt  time.Time `gorm:"not null"`
+	Announced bool      `gorm:"default:false"` // set once the unlock has been returned in an API response
+}
+
+// TableName specifies the table name for UserAchievement model
+func (UserAchievement) TableName() string {
+	return "user_achievements"
+}
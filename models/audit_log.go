@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog is an append-only record of an administrative action. Before and After
+// hold JSON snapshots of the target; either is empty when it did not exist.
+// Updates and deletes are rejected by a database trigger.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	ActorID    uint      `gorm:"index;not null"`
+	Action     string    `gorm:"size:50;index;not null"` // e.g. user.suspend, role.grant, contest.update
+	TargetType string    `gorm:"size:30;index:idx_audit_target;not null"`
+	TargetID   string    `gorm:"size:64;index:idx_audit_target"`
+	Before     *string   `gorm:"type:jsonb"`
+	After      *string   `gorm:"type:jsonb"`
+	IPAddress  string    `gorm:"size:64"`
+	RequestID  string    `gorm:"size:64"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
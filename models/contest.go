@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ContestStatus string
+
+const (
+	ContestUpcoming ContestStatus = "upcoming"
+	ContestRunning  ContestStatus = "running"
+	ContestEnded    ContestStatus = "ended"
+)
+
+type Contest struct {
+	ID                   uint      `gorm:"primaryKey"`
+	Title                string    `gorm:"size:255;not null"`
+	Description          string    `gorm:"type:text"`
+	StartTime            time.Time `gorm:"not null;index"`
+	EndTime              time.Time `gorm:"not null"`
+	FreezeMinutes        int       `gorm:"default:0"` // scoreboard is frozen this many minutes before EndTime
+	TimePenaltyPerMinute int       `gorm:"default:0"` // points deducted per minute elapsed since StartTime
+	WrongAttemptPenalty  int       `gorm:"default:0"` // points deducted per wrong attempt before the correct one
+	CreatedBy            uint      `gorm:"not null"`  // Reference to User ID
+	Questions            []ContestQuestion
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName specifies the table name for Contest model
+func (Contest) TableName() string {
+	return "contests"
+}
+
+// Status reports where the contest is in its lifecycle at the given time
+func (c Contest) Status(now time.Time) ContestStatus {
+	switch {
+	case now.Before(c.StartTime):
+		return ContestUpcoming
+	case now.Before(c.EndTime):
+		return ContestRunning
+	default:
+		return ContestEnded
+	}
+}
+
+// FreezeTime returns the moment after which the public scoreboard stops updating
+func (c Contest) FreezeTime() time.Time {
+	return c.EndTime.Add(-time.Duration(c.FreezeMinutes) * time.Minute)
+}
+
+// IsFrozen reports whether the public scoreboard is frozen at the given time.
+// The freeze is lifted as soon as the contest ends.
+func (c Contest) IsFrozen(now time.Time) bool {
+	return c.FreezeMinutes > 0 && !now.Before(c.FreezeTime()) && now.Before(c.EndTime)
+}
+
+// ContestQuestion links a question to a contest
+type ContestQuestion struct {
+	ID         uint     `gorm:"primaryKey"`
+	ContestID  uint     `gorm:"uniqueIndex:idx_contest_question;not null"`
+	QuestionID uint     `gorm:"uniqueIndex:idx_contest_question;not null"`
+	Position   int      `gorm:"not null"`
+	Points     int      `gorm:"not null"` // points awarded for a correct answer before penalties
+	Question   Question `gorm:"foreignKey:QuestionID"`
+}
+
+// TableName specifies the table name for ContestQuestion model
+func (ContestQuestion) TableName() string {
+	return "contest_questions"
+}
+
+// ContestRegistration records that a user has registered for a contest
+type ContestRegistration struct {
+	ID        uint `gorm:"primaryKey"`
+	ContestID uint `gorm:"uniqueIndex:idx_contest_registration;not null"`
+	UserID    uint `gorm:"uniqueIndex:idx_contest_registration;not null"`
+	User      User `gorm:"foreignKey:UserID"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for ContestRegistration model
+func (ContestRegistration) TableName() string {
+	return "contest_registrations"
+}
+
+// CreateContestRequest represents the request body for creating a contest
+type CreateContestRequest struct {
+	Title                string    `json:"title" binding:"required"`
+	Description          string    `json:"description"`
+	StartTime            time.Time `json:"startTime" binding:"required"`
+	EndTime              time.Time `json:"endTime" binding:"required"`
+	FreezeMinutes        int       `json:"freezeMinutes" binding:"min=0"`
+	TimePenaltyPerMinute int       `json:"timePenaltyPerMinute" binding:"min=0"`
+	WrongAttemptPenalty  int       `json:"wrongAttemptPenalty" binding:"min=0"`
+	QuestionIDs          []string  `json:"questionIds" binding:"required,min=1"`
+}
+
+// UpdateContestRequest represents the request body for updating a contest.
+// Only provided fields are changed; questions can only be replaced before the contest starts.
+type UpdateContestRequest struct {
+	Title                *string    `json:"title"`
+	Description          *string    `json:"description"`
+	StartTime            *time.Time `json:"startTime"`
+	EndTime              *time.Time `json:"endTime"`
+	FreezeMinutes        *int       `json:"freezeMinutes" binding:"omitempty,min=0"`
+	TimePenaltyPerMinute *int       `json:"timePenaltyPerMinute" binding:"omitempty,min=0"`
+	WrongAttemptPenalty  *int       `json:"wrongAttemptPenalty" binding:"omitempty,min=0"`
+	QuestionIDs          []string   `json:"questionIds" binding:"omitempty,min=1"`
+}
+
+// SubmitContestAnswerRequest represents the request body for answering a contest question
+type SubmitContestAnswerRequest struct {
+	QuestionID string `json:"questionId" binding:"required"`
+	Answer     string `json:"answer" binding:"required"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContestFreeze(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	contest := Contest{StartTime: start, EndTime: start.Add(2 * time.Hour), FreezeMinutes: 30}
+
+	if want := start.Add(90 * time.Minute); !contest.FreezeTime().Equal(want) {
+		t.Fatalf("FreezeTime = %v, want %v", contest.FreezeTime(), want)
+	}
+
+	tests := []struct {
+		name          string
+		freezeMinutes int
+		now           time.Time
+		want          bool
+	}{
+		{"before the cutoff", 30, start.Add(89 * time.Minute), false},
+		{"at the cutoff", 30, start.Add(90 * time.Minute), true},
+		{"just before the end", 30, start.Add(2*time.Hour - time.Second), true},
+		{"lifted at the end", 30, start.Add(2 * time.Hour), false},
+		{"no freeze configured", 0, start.Add(2*time.Hour - time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := contest
+			c.FreezeMinutes = tt.freezeMinutes
+			if got := c.IsFrozen(tt.now); got != tt.want {
+				t.Errorf("IsFrozen = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
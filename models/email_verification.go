@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerification is an outstanding email verification link. Only a hash of the
+// token is stored; sending a new link replaces any earlier one.
+type EmailVerification struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	Email     string    `gorm:"not null"` // address the link was sent to
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for EmailVerification model
+func (EmailVerification) TableName() string {
+	return "email_verifications"
+}
+
+// VerifyEmailRequest represents the request body for confirming an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
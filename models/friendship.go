@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "pending"
+	FriendshipAccepted FriendshipStatus = "accepted"
+)
+
+// Friendship is a friend request between two users. Once accepted it is symmetric;
+// only one row ever exists for a pair of users regardless of who sent the request,
+// enforced by the unique index idx_friendship_unordered_pair (migration 0005).
+type Friendship struct {
+	ID          uint             `gorm:"primaryKey"`
+	RequesterID uint             `gorm:"index;not null"`
+	AddresseeID uint             `gorm:"index;not null"`
+	Requester   User             `gorm:"foreignKey:RequesterID"`
+	Addressee   User             `gorm:"foreignKey:AddresseeID"`
+	Status      FriendshipStatus `gorm:"size:20;not null;default:pending"`
+	AcceptedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName specifies the table name for Friendship model
+func (Friendship) TableName() string {
+	return "friendships"
+}
+
+// OtherUser returns the side of the friendship that is not userID
+func (f Friendship) OtherUser(userID uint) User {
+	if f.RequesterID == userID {
+		return f.Addressee
+	}
+	return f.Requester
+}
+
+// UserBlock records that one user has blocked another
+type UserBlock struct {
+	ID        uint `gorm:"primaryKey"`
+	BlockerID uint `gorm:"uniqueIndex:idx_user_block;not null"`
+	BlockedID uint `gorm:"uniqueIndex:idx_user_block;index;not null"`
+	Blocked   User `gorm:"foreignKey:BlockedID"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for UserBlock model
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
+// TargetUserRequest represents the request body for actions aimed at another user,
+// such as sending a friend request or blocking
+type TargetUserRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+// ChallengeFriendRequest represents the request body for challenging a friend to a one-on-one battle
+type ChallengeFriendRequest struct {
+	Category           string `json:"category"`
+	Difficulty         string `json:"difficulty" binding:"omitempty,oneof=beginner intermediate advanced expert"`
+	QuestionCount      int    `json:"questionCount" binding:"required,min=1,max=50"`
+	SecondsPerQuestion int    `json:"secondsPerQuestion" binding:"required,min=10,max=600"`
+	Ranked             bool   `json:"ranked"`
+}
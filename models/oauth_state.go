@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthState is a pending OAuth sign-in. It is created when the flow starts and
+// deleted when the callback consumes it, so each state can be used only once.
+type OAuthState struct {
+	ID           uint      `gorm:"primaryKey"`
+	StateHash    string    `gorm:"size:64;uniqueIndex;not null"` // SHA-256 of the state sent to the provider
+	BindingHash  string    `gorm:"size:64;not null"`             // SHA-256 of the nonce stored in the initiating client's cookie
+	CodeVerifier string    `gorm:"size:128;not null"`            // PKCE verifier, never sent to the client
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
+
+// TableName specifies the table name for OAuthState model
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// UserRole grants a role to a user. Every user is implicitly a player, so only
+// additional roles are stored. Role names are defined in the rbac package.
+type UserRole struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_role;not null"`
+	Role      string    `gorm:"size:50;uniqueIndex:idx_user_role;not null"`
+	GrantedBy *uint     // nil when granted by the system, e.g. a backfill
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for UserRole model
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// RoleChange is an append-only record of a role being granted or revoked
+type RoleChange struct {
+	ID        uint   `gorm:"primaryKey"`
+	ActorID   uint   `gorm:"index;not null"` // user who made the change
+	TargetID  uint   `gorm:"index;not null"` // user whose roles changed
+	Role      string `gorm:"size:50;not null"`
+	Action    string `gorm:"size:10;not null"` // grant or revoke
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for RoleChange model
+func (RoleChange) TableName() string {
+	return "role_changes"
+}
+
+// GrantRoleRequest represents the request body for granting a role
+type GrantRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
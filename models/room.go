@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+)
+
+type RoomStatus string
+
+const (
+	RoomWaiting    RoomStatus = "waiting"
+	RoomInProgress RoomStatus = "in_progress"
+	RoomFinished   RoomStatus = "finished"
+)
+
+// Room is a private battle that players join with an invite code
+type Room struct {
+	ID                 uint       `gorm:"primaryKey"`
+	InviteCode         string     `gorm:"size:12;uniqueIndex;not null"`
+	HostID             uint       `gorm:"index;not null"` // Reference to User ID
+	Category           string     `gorm:"size:100"`       // empty means any category
+	Difficulty         string     `gorm:"size:20"`        // empty means any difficulty
+	QuestionCount      int        `gorm:"not null"`
+	SecondsPerQuestion int        `gorm:"not null"`
+	MaxParticipants    int        `gorm:"not null"`
+	Ranked             bool       `gorm:"default:false"` // ranked rooms update player ratings when finished
+	ChallengedID       *uint      `gorm:"index"`         // set for friend challenges; only this user may join
+	Status             RoomStatus `gorm:"size:20;not null;default:waiting"`
+	StartedAt          *time.Time
+	FinishedAt         *time.Time
+	Participants       []RoomParticipant
+	Questions          []RoomQuestion
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// TableName specifies the table name for Room model
+func (Room) TableName() string {
+	return "rooms"
+}
+
+// EndsAt returns when the last question closes, or nil if the room has not started
+func (r Room) EndsAt() *time.Time {
+	if r.StartedAt == nil {
+		return nil
+	}
+	end := r.StartedAt.Add(time.Duration(r.QuestionCount*r.SecondsPerQuestion) * time.Second)
+	return &end
+}
+
+// CurrentQuestionIndex returns the zero-based index of the question open at the given time,
+// or -1 when no question is open
+func (r Room) CurrentQuestionIndex(now time.Time) int {
+	if r.Status != RoomInProgress || r.StartedAt == nil || now.Before(*r.StartedAt) {
+		return -1
+	}
+	index := int(now.Sub(*r.StartedAt) / (time.Duration(r.SecondsPerQuestion) * time.Second))
+	if index >= r.QuestionCount {
+		return -1
+	}
+	return index
+}
+
+// RoomParticipant records a user's membership and final result in a room
+type RoomParticipant struct {
+	ID           uint `gorm:"primaryKey"`
+	RoomID       uint `gorm:"uniqueIndex:idx_room_participant;not null"`
+	UserID       uint `gorm:"uniqueIndex:idx_room_participant;not null"`
+	User         User `gorm:"foreignKey:UserID"`
+	Score        int  `gorm:"default:0"`
+	Correct      int  `gorm:"default:0"`
+	Rank         int  `gorm:"default:0"` // set when the room finishes
+	RatingChange int  `gorm:"default:0"` // set when a ranked room finishes
+	CreatedAt    time.Time
+}
+
+// TableName specifies the table name for RoomParticipant model
+func (RoomParticipant) TableName() string {
+	return "room_participants"
+}
+
+// RoomQuestion links a question to a room
+type RoomQuestion struct {
+	ID         uint     `gorm:"primaryKey"`
+	RoomID     uint     `gorm:"uniqueIndex:idx_room_question;not null"`
+	QuestionID uint     `gorm:"uniqueIndex:idx_room_question;not null"`
+	Position   int      `gorm:"not null"`
+	Question   Question `gorm:"foreignKey:QuestionID"`
+}
+
+// TableName specifies the table name for RoomQuestion model
+func (RoomQuestion) TableName() string {
+	return "room_questions"
+}
+
+// CreateRoomRequest represents the request body for creating a private room
+type CreateRoomRequest struct {
+	Category           string `json:"category"`
+	Difficulty         string `json:"difficulty" binding:"omitempty,oneof=beginner intermediate advanced expert"`
+	QuestionCount      int    `json:"questionCount" binding:"required,min=1,max=50"`
+	SecondsPerQuestion int    `json:"secondsPerQuestion" binding:"required,min=10,max=600"`
+	MaxParticipants    int    `json:"maxParticipants" binding:"required,min=2,max=50"`
+	Ranked             bool   `json:"ranked"`
+}
+
+// JoinRoomRequest represents the request body for joining a room by invite code
+type JoinRoomRequest struct {
+	InviteCode string `json:"inviteCode" binding:"required"`
+}
+
+// SubmitRoomAnswerRequest represents the request body for answering the current room question
+type SubmitRoomAnswerRequest struct {
+	QuestionID string `json:"questionId" binding:"required"`
+	Answer     string `json:"answer" binding:"required"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// UserSession is a signed-in device. It is created at sign-in and holds a hash of
+// the refresh token so the session can be refreshed, listed and revoked.
+type UserSession struct {
+	ID               uint   `gorm:"primaryKey"`
+	SessionID        string `gorm:"size:36;uniqueIndex;not null"` // public identifier
+	UserID           uint   `gorm:"index;not null"`
+	RefreshTokenHash string `gorm:"size:64;uniqueIndex;not null"`
+	UserAgent        string `gorm:"size:512"`
+	IPAddress        string `gorm:"size:64"`
+	LastUsedAt       time.Time
+	RevokedAt        *time.Time `gorm:"index"`
+	CreatedAt        time.Time
+}
+
+// TableName specifies the table name for UserSession model
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// RefreshTokenRequest represents the request body for refreshing an ID token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Submission is a single answer attempt by a user
+type Submission struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index;not null"`
+	QuestionID uint   `gorm:"index;not null"`
+	ContestID  *uint  `gorm:"index"` // set when the answer was submitted in a contest
+	RoomID     *uint  `gorm:"index"` // set when the answer was submitted in a room battle
+	Answer     string `gorm:"type:text;not null"`
+	IsCorrect  bool   `gorm:"default:false"`
+	Points     int    `gorm:"default:0"` // points awarded after penalties
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name for Submission model
+func (Submission) TableName() string {
+	return "submissions"
+}
@@ -6,22 +6,76 @@ import (
 	"gorm.io/gorm"
 )
 
+// Visibility controls who can see a profile field on the public profile
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityFriends Visibility = "friends"
+	VisibilityPrivate Visibility = "private"
+)
+
 type User struct {
-	ID          uint   `gorm:"primaryKey"`
-	FirebaseUID string `gorm:"unique;not null"`
-	Email       string `gorm:"unique;not null"`
-	IsAdmin     bool   `gorm:"default:false"`
-	DisplayName string `gorm:"size:255"`
-	PhotoURL    string `gorm:"size:512"`
-	Phone       string `gorm:"size:20"`
-	Country     string `gorm:"size:100"`
-	Bio         string `gorm:"type:text"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	ID            uint   `gorm:"primaryKey"`
+	FirebaseUID   string `gorm:"unique;not null"`
+	Email         string `gorm:"unique;not null"`
+	IsAdmin       bool   `gorm:"default:false"`
+	EmailVerified bool   `gorm:"default:false"` // mirrors the identity provider's flag
+	DisplayName   string `gorm:"size:255"`
+	PhotoURL      string `gorm:"size:512"`
+	Phone         string `gorm:"size:20"`
+	Country       string `gorm:"size:100"`
+	Bio           string `gorm:"type:text"`
+	Rating        int    `gorm:"default:1200"` // updated by ranked battles
+
+	// Suspended accounts are disabled with the identity provider and rejected by AuthMiddleware
+	SuspendedAt      *time.Time `gorm:"index"`
+	SuspensionReason string     `gorm:"size:500"`
+
+	// Privacy settings for the public profile; display name and photo are always public
+	EmailVisibility        Visibility `gorm:"size:10;default:private"`
+	PhoneVisibility        Visibility `gorm:"size:10;default:private"`
+	CountryVisibility      Visibility `gorm:"size:10;default:public"`
+	BioVisibility          Visibility `gorm:"size:10;default:public"`
+	RatingVisibility       Visibility `gorm:"size:10;default:public"`
+	AchievementsVisibility Visibility `gorm:"size:10;default:public"`
+	StatsVisibility        Visibility `gorm:"size:10;default:public"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 // TableName specifies the table name for User model
 func (User) TableName() string {
 	return "users"
 }
+
+// PrivacySettings returns the visibility of each optional profile field keyed by field name
+func (u User) PrivacySettings() map[string]Visibility {
+	return map[string]Visibility{
+		"email":        u.EmailVisibility,
+		"phone":        u.PhoneVisibility,
+		"country":      u.CountryVisibility,
+		"bio":          u.BioVisibility,
+		"rating":       u.RatingVisibility,
+		"achievements": u.AchievementsVisibility,
+		"stats":        u.StatsVisibility,
+	}
+}
+
+// UpdatePrivacyRequest represents the request body for changing profile privacy settings
+type UpdatePrivacyRequest struct {
+	Email        *Visibility `json:"email" binding:"omitempty,oneof=public friends private"`
+	Phone        *Visibility `json:"phone" binding:"omitempty,oneof=public friends private"`
+	Country      *Visibility `json:"country" binding:"omitempty,oneof=public friends private"`
+	Bio          *Visibility `json:"bio" binding:"omitempty,oneof=public friends private"`
+	Rating       *Visibility `json:"rating" binding:"omitempty,oneof=public friends private"`
+	Achievements *Visibility `json:"achievements" binding:"omitempty,oneof=public friends private"`
+	Stats        *Visibility `json:"stats" binding:"omitempty,oneof=public friends private"`
+}
+
+// SuspendUserRequest represents the request body for suspending a user
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,138 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"gorm.io/gorm"
+)
+
+// RolesClaim is the custom claim that mirrors a user's stored roles
+const RolesClaim = "roles"
+
+// RolesFromClaims reads the roles mirrored into verified token claims, including the
+// implicit player role. ok is false when the token carries no roles claim, for example
+// because it was issued before the roles were synced, and the database must be consulted.
+func RolesFromClaims(claims map[string]interface{}) (roles []Role, ok bool) {
+	var names []string
+	switch raw := claims[RolesClaim].(type) {
+	case []interface{}: // decoded from JSON
+		for _, value := range raw {
+			name, _ := value.(string)
+			names = append(names, name)
+		}
+	case []string:
+		names = raw
+	default:
+		return nil, false
+	}
+
+	roles = []Role{RolePlayer}
+	for _, name := range names {
+		if role, err := Parse(name); err == nil && role != RolePlayer {
+			roles = append(roles, role)
+		}
+	}
+	return roles, true
+}
+
+// SyncClaims mirrors the user's stored roles into their identity provider claims,
+// keeping any other custom claims the account has
+func SyncClaims(ctx context.Context, provider identity.IdentityProvider, user models.User, roles []Role) error {
+	record, err := provider.GetUser(ctx, user.FirebaseUID)
+	if err != nil {
+		return err
+	}
+	return provider.SetCustomClaims(ctx, user.FirebaseUID, claimsWithRoles(record.CustomClaims, roles))
+}
+
+// ReconcileResult summarizes a Reconcile run
+type ReconcileResult struct {
+	Checked int // users compared
+	Updated int // users whose claims differed (and were fixed unless dry run)
+	Missing int // users without an identity provider account
+}
+
+// Reconcile compares every user's claims with the roles stored in the database, which
+// is the source of truth, and rewrites claims that have drifted. With dryRun nothing is written.
+func Reconcile(ctx context.Context, db *gorm.DB, provider identity.IdentityProvider, dryRun bool) (ReconcileResult, error) {
+	var result ReconcileResult
+	var users []models.User
+
+	err := db.FindInBatches(&users, 100, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			result.Checked++
+
+			record, err := provider.GetUser(ctx, user.FirebaseUID)
+			if errors.Is(err, identity.ErrUserNotFound) {
+				result.Missing++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("user %d: %w", user.ID, err)
+			}
+
+			roles, err := RolesOf(db, user.ID)
+			if err != nil {
+				return fmt.Errorf("user %d: %w", user.ID, err)
+			}
+			current, _ := RolesFromClaims(record.CustomClaims)
+			if sameRoles(current, roles) {
+				continue
+			}
+
+			result.Updated++
+			if dryRun {
+				continue
+			}
+			if err := provider.SetCustomClaims(ctx, user.FirebaseUID, claimsWithRoles(record.CustomClaims, roles)); err != nil {
+				return fmt.Errorf("user %d: %w", user.ID, err)
+			}
+		}
+		return nil
+	}).Error
+	return result, err
+}
+
+func claimsWithRoles(existing map[string]interface{}, roles []Role) map[string]interface{} {
+	claims := make(map[string]interface{}, len(existing)+1)
+	for name, value := range existing {
+		claims[name] = value
+	}
+
+	// The implicit player role is left out to keep tokens small
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role != RolePlayer {
+			names = append(names, string(role))
+		}
+	}
+	claims[RolesClaim] = names
+	return claims
+}
+
+// sameRoles compares two role lists as sets. A nil list (no claim) never matches.
+func sameRoles(a, b []Role) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	sorted := func(roles []Role) []string {
+		names := make([]string, len(roles))
+		for i, role := range roles {
+			names[i] = string(role)
+		}
+		sort.Strings(names)
+		return names
+	}
+	x, y := sorted(a), sorted(b)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+package rbac
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Role is a named set of permissions
+type Role string
+
+const (
+	RolePlayer         Role = "player" // implicit for every user; never stored
+	RoleQuestionAuthor Role = "question-author"
+	RoleReviewer       Role = "reviewer"
+	RoleModerator      Role = "moderator"
+	RoleAdmin          Role = "admin"
+)
+
+// Permission guards an operation
+type Permission string
+
+const (
+	PermissionCreateQuestions Permission = "questions:create"
+	PermissionReviewQuestions Permission = "questions:review"
+	PermissionManageContests  Permission = "contests:manage"
+	PermissionModerateUsers   Permission = "users:moderate"
+	PermissionManageRoles     Permission = "roles:manage"
+	PermissionReadAudit       Permission = "audit:read"
+)
+
+// Permissions maps each role to the permissions it grants
+var Permissions = map[Role][]Permission{
+	RolePlayer:         {},
+	RoleQuestionAuthor: {PermissionCreateQuestions},
+	RoleReviewer:       {PermissionCreateQuestions, PermissionReviewQuestions},
+	RoleModerator:      {PermissionModerateUsers},
+	RoleAdmin: {
+		PermissionCreateQuestions,
+		PermissionReviewQuestions,
+		PermissionManageContests,
+		PermissionModerateUsers,
+		PermissionManageRoles,
+		PermissionReadAudit,
+	},
+}
+
+// Roles lists the roles in display order
+var Roles = []Role{RolePlayer, RoleQuestionAuthor, RoleReviewer, RoleModerator, RoleAdmin}
+
+var (
+	ErrUnknownRole   = errors.New("unknown role")
+	ErrImplicitRole  = errors.New("every user has the player role")
+	ErrRoleHeld      = errors.New("user already has this role")
+	ErrRoleNotHeld   = errors.New("user does not have this role")
+	ErrLastAdmin     = errors.New("cannot revoke the last admin")
+	errInvalidAction = errors.New("invalid role change action")
+)
+
+// Actions recorded in the role change log
+const (
+	ActionGrant  = "grant"
+	ActionRevoke = "revoke"
+)
+
+// Parse validates a role name
+func Parse(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := Permissions[role]; !ok {
+		return "", ErrUnknownRole
+	}
+	return role, nil
+}
+
+// Has reports whether any of the roles grants the permission
+func Has(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		for _, p := range Permissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RolesOf returns the user's roles, including the implicit player role
+func RolesOf(db *gorm.DB, userID uint) ([]Role, error) {
+	var names []string
+	if err := db.Model(&models.UserRole{}).Where("user_id = ?", userID).Order("role").Pluck("role", &names).Error; err != nil {
+		return nil, err
+	}
+
+	roles := []Role{RolePlayer}
+	for _, name := range names {
+		roles = append(roles, Role(name))
+	}
+	return roles, nil
+}
+
+// Grant gives the target user a role and records the change.
+// It keeps users.is_admin in step with the admin role.
+func Grant(db *gorm.DB, actorID, targetID uint, role Role) error {
+	return change(db, actorID, targetID, role, ActionGrant)
+}
+
+// Revoke removes a role from the target user and records the change.
+// The last remaining admin cannot be revoked.
+func Revoke(db *gorm.DB, actorID, targetID uint, role Role) error {
+	return change(db, actorID, targetID, role, ActionRevoke)
+}
+
+// RevokeAll removes every stored role from the target user, leaving only the implicit
+// player role, and records each change. It returns the roles that were removed.
+func RevokeAll(db *gorm.DB, actorID, targetID uint) ([]Role, error) {
+	var revoked []Role
+	err := db.Transaction(func(tx *gorm.DB) error {
+		roles, err := RolesOf(tx, targetID)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if role == RolePlayer {
+				continue
+			}
+			if err := applyChange(tx, actorID, targetID, role, ActionRevoke); err != nil {
+				return err
+			}
+			revoked = append(revoked, role)
+		}
+		return nil
+	})
+	return revoked, err
+}
+
+func change(db *gorm.DB, actorID, targetID uint, role Role, action string) error {
+	if role == RolePlayer {
+		return ErrImplicitRole
+	}
+	if _, ok := Permissions[role]; !ok {
+		return ErrUnknownRole
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return applyChange(tx, actorID, targetID, role, action)
+	})
+}
+
+// applyChange grants or revokes a role within the caller's transaction
+func applyChange(tx *gorm.DB, actorID, targetID uint, role Role, action string) error {
+	switch action {
+	case ActionGrant:
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{
+			UserID:    targetID,
+			Role:      string(role),
+			GrantedBy: &actorID,
+			CreatedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleHeld
+		}
+	case ActionRevoke:
+		if role == RoleAdmin {
+			// Lock the admin rows so two admins cannot revoke each other at the same time
+			var admins []models.UserRole
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("role = ?", string(RoleAdmin)).Find(&admins).Error; err != nil {
+				return err
+			}
+			if len(admins) == 1 && admins[0].UserID == targetID {
+				return ErrLastAdmin
+			}
+		}
+		result := tx.Where("user_id = ? AND role = ?", targetID, string(role)).Delete(&models.UserRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleNotHeld
+		}
+	default:
+		return errInvalidAction
+	}
+
+	if role == RoleAdmin {
+		if err := tx.Model(&models.User{}).Where("id = ?", targetID).
+			Update("is_admin", action == ActionGrant).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&models.RoleChange{
+		ActorID:  actorID,
+		TargetID: targetID,
+		Role:     string(role),
+		Action:   action,
+	}).Error
+}
@@ -0,0 +1,785 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"gorm.io/gorm"
+)
+
+// memoryData is everything a memory store holds. Transactions snapshot it
+// and restore the snapshot on rollback.
+type memoryData struct {
+	nextID        uint
+	users         map[uint]models.User
+	roles         []models.UserRole
+	roleChanges   []models.RoleChange
+	verifications []models.EmailVerification
+	sessions      []models.UserSession
+	oauthStates   []models.OAuthState
+	achievements  []models.UserAchievement
+	questions     []models.Question
+	audit         []models.AuditLog
+}
+
+func (d *memoryData) clone() *memoryData {
+	users := make(map[uint]models.User, len(d.users))
+	for id, user := range d.users {
+		users[id] = user
+	}
+	return &memoryData{
+		nextID:        d.nextID,
+		users:         users,
+		roles:         append([]models.UserRole(nil), d.roles...),
+		roleChanges:   append([]models.RoleChange(nil), d.roleChanges...),
+		verifications: append([]models.EmailVerification(nil), d.verifications...),
+		sessions:      append([]models.UserSession(nil), d.sessions...),
+		oauthStates:   append([]models.OAuthState(nil), d.oauthStates...),
+		achievements:  append([]models.UserAchievement(nil), d.achievements...),
+		questions:     append([]models.Question(nil), d.questions...),
+		audit:         append([]models.AuditLog(nil), d.audit...),
+	}
+}
+
+func (d *memoryData) id() uint {
+	d.nextID++
+	return d.nextID
+}
+
+type memoryStore struct {
+	mu   *sync.Mutex
+	data **memoryData
+	inTx bool // the mutex is already held by an enclosing Transaction
+}
+
+// NewMemoryStore returns an empty Store held in memory, for tests.
+// It is safe for concurrent use; transactions are serialized.
+func NewMemoryStore() Store {
+	data := &memoryData{users: map[uint]models.User{}}
+	return &memoryStore{mu: &sync.Mutex{}, data: &data}
+}
+
+func (s *memoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *memoryStore) Users() UserRepository {
+	return &memoryUsers{s: s}
+}
+
+func (s *memoryStore) Sessions() SessionRepository {
+	return &memorySessions{s: s}
+}
+
+func (s *memoryStore) OAuthStates() OAuthStateRepository {
+	return &memoryOAuthStates{s: s}
+}
+
+func (s *memoryStore) Achievements() AchievementRepository {
+	return &memoryAchievements{s: s}
+}
+
+func (s *memoryStore) Exports() ExportRepository {
+	return &memoryExports{s: s}
+}
+
+func (s *memoryStore) Questions() QuestionRepository {
+	return &memoryQuestions{s: s}
+}
+
+func (s *memoryStore) Audit() AuditRepository {
+	return &memoryAudit{s: s}
+}
+
+func (s *memoryStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	defer s.lock()()
+
+	snapshot := (*s.data).clone()
+	if err := fn(&memoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = snapshot
+		return err
+	}
+	return nil
+}
+
+type memoryUsers struct {
+	s *memoryStore
+}
+
+// findBy returns the first user matching, skipping soft-deleted ones as GORM does
+func (r *memoryUsers) findBy(match func(models.User) bool) (models.User, error) {
+	for _, user := range (*r.s.data).users {
+		if !user.DeletedAt.Valid && match(user) {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUsers) FindByID(ctx context.Context, id uint) (models.User, error) {
+	defer r.s.lock()()
+	return r.findBy(func(u models.User) bool { return u.ID == id })
+}
+
+func (r *memoryUsers) FindByUID(ctx context.Context, uid string) (models.User, error) {
+	defer r.s.lock()()
+	return r.findBy(func(u models.User) bool { return u.FirebaseUID == uid })
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	defer r.s.lock()()
+	return r.create(user)
+}
+
+func (r *memoryUsers) create(user *models.User) error {
+	data := *r.s.data
+	for _, existing := range data.users {
+		if existing.FirebaseUID == user.FirebaseUID || existing.Email == user.Email {
+			return ErrConflict
+		}
+	}
+
+	// Mirror the column defaults declared on the model
+	if user.Rating == 0 {
+		user.Rating = 1200
+	}
+	for field, value := range map[*models.Visibility]models.Visibility{
+		&user.EmailVisibility:        models.VisibilityPrivate,
+		&user.PhoneVisibility:        models.VisibilityPrivate,
+		&user.CountryVisibility:      models.VisibilityPublic,
+		&user.BioVisibility:          models.VisibilityPublic,
+		&user.RatingVisibility:       models.VisibilityPublic,
+		&user.AchievementsVisibility: models.VisibilityPublic,
+		&user.StatsVisibility:        models.VisibilityPublic,
+	} {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	now := time.Now()
+	user.ID = data.id()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	data.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUsers) Provision(ctx context.Context, uid string, claims map[string]interface{}) (models.User, bool, error) {
+	defer r.s.lock()()
+
+	for _, user := range (*r.s.data).users {
+		if user.FirebaseUID != uid {
+			continue
+		}
+		if user.DeletedAt.Valid {
+			return models.User{}, false, ErrAccountDeleted
+		}
+		return user, false, nil
+	}
+	user, err := userFromClaims(uid, claims)
+	if err != nil {
+		return user, false, err
+	}
+	if err := r.create(&user); err != nil {
+		return user, false, err
+	}
+	return user, true, nil
+}
+
+func (r *memoryUsers) UpdateProfile(ctx context.Context, id uint, update ProfileUpdate) (models.User, error) {
+	defer r.s.lock()()
+	if err := r.update(id, func(user *models.User) error {
+		update.apply(user)
+		return nil
+	}); err != nil {
+		return models.User{}, err
+	}
+	return (*r.s.data).users[id], nil
+}
+
+// DeleteAccount removes what the memory store holds: the row, roles, verifications,
+// sessions and achievements
+func (r *memoryUsers) DeleteAccount(ctx context.Context, id uint, purge bool) error {
+	defer r.s.lock()()
+	data := *r.s.data
+	user, ok := data.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	var sessions []models.UserSession
+	for _, session := range data.sessions {
+		if session.UserID != id {
+			sessions = append(sessions, session)
+		}
+	}
+	data.sessions = sessions
+	var earned []models.UserAchievement
+	for _, achievement := range data.achievements {
+		if achievement.UserID != id {
+			earned = append(earned, achievement)
+		}
+	}
+	data.achievements = earned
+
+	var roles []models.UserRole
+	for _, role := range data.roles {
+		if role.UserID != id {
+			roles = append(roles, role)
+		}
+	}
+	data.roles = roles
+	var verifications []models.EmailVerification
+	for _, verification := range data.verifications {
+		if verification.UserID != id {
+			verifications = append(verifications, verification)
+		}
+	}
+	data.verifications = verifications
+
+	if purge {
+		delete(data.users, id)
+		return nil
+	}
+	now := time.Now()
+	user.Email = fmt.Sprintf("deleted-%d@deleted.invalid", id)
+	user.EmailVerified = false
+	user.IsAdmin = false
+	user.DisplayName = "Deleted user"
+	user.PhotoURL, user.Phone, user.Country, user.Bio = "", "", "", ""
+	user.UpdatedAt = now
+	user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	data.users[id] = user
+	return nil
+}
+
+// update applies fn to a stored user
+func (r *memoryUsers) update(id uint, fn func(user *models.User) error) error {
+	data := *r.s.data
+	user, ok := data.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if err := fn(&user); err != nil {
+		return err
+	}
+	user.UpdatedAt = time.Now()
+	data.users[id] = user
+	return nil
+}
+
+func (r *memoryUsers) SetEmailVerified(ctx context.Context, id uint, verified bool) error {
+	defer r.s.lock()()
+	return r.update(id, func(user *models.User) error {
+		user.EmailVerified = verified
+		return nil
+	})
+}
+
+func (r *memoryUsers) Suspend(ctx context.Context, id uint, reason string, at time.Time) error {
+	defer r.s.lock()()
+	err := r.update(id, func(user *models.User) error {
+		if user.SuspendedAt != nil {
+			return ErrAlreadySuspended
+		}
+		user.SuspendedAt = &at
+		user.SuspensionReason = reason
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	revokeSessions(*r.s.data, id, at)
+	return nil
+}
+
+func (r *memoryUsers) Unsuspend(ctx context.Context, id uint) error {
+	defer r.s.lock()()
+	return r.update(id, func(user *models.User) error {
+		if user.SuspendedAt == nil {
+			return ErrNotSuspended
+		}
+		user.SuspendedAt = nil
+		user.SuspensionReason = ""
+		return nil
+	})
+}
+
+func (r *memoryUsers) List(ctx context.Context, filter UserFilter, page, pageSize int) ([]models.User, int64, error) {
+	defer r.s.lock()()
+	data := *r.s.data
+
+	var matches []models.User
+	for _, user := range data.users {
+		switch {
+		case user.DeletedAt.Valid:
+		case filter.Email != "" && !strings.Contains(strings.ToLower(user.Email), strings.ToLower(filter.Email)):
+		case filter.Country != "" && !strings.EqualFold(user.Country, filter.Country):
+		case filter.Role != "" && filter.Role != rbac.RolePlayer && !r.hasRole(user.ID, filter.Role):
+		case filter.CreatedAfter != nil && user.CreatedAt.Before(*filter.CreatedAfter):
+		case filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore):
+		case filter.Suspended != nil && *filter.Suspended != (user.SuspendedAt != nil):
+		default:
+			matches = append(matches, user)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+
+	total := int64(len(matches))
+	start := (page - 1) * pageSize
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], total, nil
+}
+
+func (r *memoryUsers) hasRole(id uint, role rbac.Role) bool {
+	for _, grant := range (*r.s.data).roles {
+		if grant.UserID == id && grant.Role == string(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUsers) roles(id uint) []rbac.Role {
+	var names []string
+	for _, grant := range (*r.s.data).roles {
+		if grant.UserID == id {
+			names = append(names, grant.Role)
+		}
+	}
+	sort.Strings(names)
+
+	roles := []rbac.Role{rbac.RolePlayer}
+	for _, name := range names {
+		roles = append(roles, rbac.Role(name))
+	}
+	return roles
+}
+
+func (r *memoryUsers) Roles(ctx context.Context, id uint) ([]rbac.Role, error) {
+	defer r.s.lock()()
+	return r.roles(id), nil
+}
+
+func (r *memoryUsers) RolesByUser(ctx context.Context, ids []uint) (map[uint][]rbac.Role, error) {
+	defer r.s.lock()()
+	roles := make(map[uint][]rbac.Role, len(ids))
+	for _, id := range ids {
+		roles[id] = r.roles(id)
+	}
+	return roles, nil
+}
+
+func (r *memoryUsers) GrantRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error {
+	defer r.s.lock()()
+	return r.change(actorID, targetID, role, rbac.ActionGrant)
+}
+
+func (r *memoryUsers) RevokeRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error {
+	defer r.s.lock()()
+	return r.change(actorID, targetID, role, rbac.ActionRevoke)
+}
+
+func (r *memoryUsers) RevokeAllRoles(ctx context.Context, actorID, targetID uint) ([]rbac.Role, error) {
+	defer r.s.lock()()
+
+	snapshot := (*r.s.data).clone()
+	var revoked []rbac.Role
+	for _, role := range r.roles(targetID) {
+		if role == rbac.RolePlayer {
+			continue
+		}
+		if err := r.change(actorID, targetID, role, rbac.ActionRevoke); err != nil {
+			*r.s.data = snapshot
+			return nil, err
+		}
+		revoked = append(revoked, role)
+	}
+	return revoked, nil
+}
+
+// change mirrors rbac.Grant and rbac.Revoke
+func (r *memoryUsers) change(actorID, targetID uint, role rbac.Role, action string) error {
+	if role == rbac.RolePlayer {
+		return rbac.ErrImplicitRole
+	}
+	if _, ok := rbac.Permissions[role]; !ok {
+		return rbac.ErrUnknownRole
+	}
+
+	data := *r.s.data
+	held := r.hasRole(targetID, role)
+	if action == rbac.ActionGrant {
+		if held {
+			return rbac.ErrRoleHeld
+		}
+		data.roles = append(data.roles, models.UserRole{
+			ID:        data.id(),
+			UserID:    targetID,
+			Role:      string(role),
+			GrantedBy: &actorID,
+			CreatedAt: time.Now(),
+		})
+	} else {
+		if !held {
+			return rbac.ErrRoleNotHeld
+		}
+		if role == rbac.RoleAdmin {
+			admins := 0
+			for _, grant := range data.roles {
+				if grant.Role == string(rbac.RoleAdmin) {
+					admins++
+				}
+			}
+			if admins == 1 {
+				return rbac.ErrLastAdmin
+			}
+		}
+		kept := data.roles[:0:0]
+		for _, grant := range data.roles {
+			if grant.UserID != targetID || grant.Role != string(role) {
+				kept = append(kept, grant)
+			}
+		}
+		data.roles = kept
+	}
+
+	if role == rbac.RoleAdmin {
+		if err := r.update(targetID, func(user *models.User) error {
+			user.IsAdmin = action == rbac.ActionGrant
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	data.roleChanges = append(data.roleChanges, models.RoleChange{
+		ID:        data.id(),
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Role:      string(role),
+		Action:    action,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (r *memoryUsers) RoleChanges(ctx context.Context, targetID uint, limit int) ([]models.RoleChange, error) {
+	defer r.s.lock()()
+
+	var changes []models.RoleChange
+	all := (*r.s.data).roleChanges
+	for i := len(all) - 1; i >= 0 && len(changes) < limit; i-- {
+		if targetID == 0 || all[i].TargetID == targetID {
+			changes = append(changes, all[i])
+		}
+	}
+	return changes, nil
+}
+
+func (r *memoryUsers) ReplaceEmailVerification(ctx context.Context, verification *models.EmailVerification, cooldown time.Duration) error {
+	defer r.s.lock()()
+	data := *r.s.data
+
+	if _, ok := data.users[verification.UserID]; !ok {
+		return ErrNotFound
+	}
+	kept := data.verifications[:0:0]
+	for _, previous := range data.verifications {
+		if previous.UserID != verification.UserID {
+			kept = append(kept, previous)
+		} else if time.Since(previous.CreatedAt) < cooldown {
+			return ErrCooldown
+		}
+	}
+
+	verification.ID = data.id()
+	verification.CreatedAt = time.Now()
+	data.verifications = append(kept, *verification)
+	return nil
+}
+
+func (r *memoryUsers) ConsumeEmailVerification(ctx context.Context, tokenHash string) (models.EmailVerification, error) {
+	defer r.s.lock()()
+	data := *r.s.data
+
+	for i, verification := range data.verifications {
+		if verification.TokenHash == tokenHash {
+			data.verifications = append(data.verifications[:i:i], data.verifications[i+1:]...)
+			return verification, nil
+		}
+	}
+	return models.EmailVerification{}, ErrNotFound
+}
+
+type memorySessions struct {
+	s *memoryStore
+}
+
+func (r *memorySessions) Create(ctx context.Context, session *models.UserSession) error {
+	defer r.s.lock()()
+	data := *r.s.data
+	session.ID = data.id()
+	session.CreatedAt = time.Now()
+	data.sessions = append(data.sessions, *session)
+	return nil
+}
+
+// find returns the index of the first unrevoked session matching, or -1
+func (r *memorySessions) find(match func(models.UserSession) bool) int {
+	for i, session := range (*r.s.data).sessions {
+		if session.RevokedAt == nil && match(session) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *memorySessions) FindActiveByTokenHash(ctx context.Context, tokenHash string) (models.UserSession, error) {
+	defer r.s.lock()()
+	i := r.find(func(s models.UserSession) bool { return s.RefreshTokenHash == tokenHash })
+	if i < 0 {
+		return models.UserSession{}, ErrNotFound
+	}
+	return (*r.s.data).sessions[i], nil
+}
+
+func (r *memorySessions) FindActive(ctx context.Context, userID uint, sessionID string) (models.UserSession, error) {
+	defer r.s.lock()()
+	i := r.find(func(s models.UserSession) bool { return s.UserID == userID && s.SessionID == sessionID })
+	if i < 0 {
+		return models.UserSession{}, ErrNotFound
+	}
+	return (*r.s.data).sessions[i], nil
+}
+
+func (r *memorySessions) ListActive(ctx context.Context, userID uint) ([]models.UserSession, error) {
+	defer r.s.lock()()
+	var sessions []models.UserSession
+	for _, session := range (*r.s.data).sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+func (r *memorySessions) Rotate(ctx context.Context, id uint, oldHash string, rotation SessionRotation) error {
+	defer r.s.lock()()
+	i := r.find(func(s models.UserSession) bool { return s.ID == id && s.RefreshTokenHash == oldHash })
+	if i < 0 {
+		return ErrNotFound
+	}
+	session := &(*r.s.data).sessions[i]
+	session.RefreshTokenHash = rotation.RefreshTokenHash
+	session.UserAgent = rotation.UserAgent
+	session.IPAddress = rotation.IPAddress
+	session.LastUsedAt = rotation.LastUsedAt
+	return nil
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, id uint, at time.Time) error {
+	defer r.s.lock()()
+	if i := r.find(func(s models.UserSession) bool { return s.ID == id }); i >= 0 {
+		(*r.s.data).sessions[i].RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *memorySessions) RevokeAll(ctx context.Context, userID uint, at time.Time) (int64, error) {
+	defer r.s.lock()()
+	return revokeSessions(*r.s.data, userID, at), nil
+}
+
+func (r *memorySessions) DeleteRevoked(ctx context.Context, before time.Time) (int64, error) {
+	defer r.s.lock()()
+	data := *r.s.data
+	kept := data.sessions[:0:0]
+	for _, session := range data.sessions {
+		if session.RevokedAt == nil || !session.RevokedAt.Before(before) {
+			kept = append(kept, session)
+		}
+	}
+	deleted := int64(len(data.sessions) - len(kept))
+	data.sessions = kept
+	return deleted, nil
+}
+
+// revokeSessions revokes the user's unrevoked sessions and returns how many there were
+func revokeSessions(data *memoryData, userID uint, at time.Time) int64 {
+	var revoked int64
+	for i := range data.sessions {
+		if data.sessions[i].UserID == userID && data.sessions[i].RevokedAt == nil {
+			data.sessions[i].RevokedAt = &at
+			revoked++
+		}
+	}
+	return revoked
+}
+
+type memoryOAuthStates struct {
+	s *memoryStore
+}
+
+func (r *memoryOAuthStates) Create(ctx context.Context, state *models.OAuthState) error {
+	defer r.s.lock()()
+	data := *r.s.data
+	state.ID = data.id()
+	state.CreatedAt = time.Now()
+	data.oauthStates = append(data.oauthStates, *state)
+	return nil
+}
+
+func (r *memoryOAuthStates) Consume(ctx context.Context, stateHash string) (models.OAuthState, error) {
+	defer r.s.lock()()
+	data := *r.s.data
+	for i, state := range data.oauthStates {
+		if state.StateHash == stateHash {
+			data.oauthStates = append(data.oauthStates[:i:i], data.oauthStates[i+1:]...)
+			return state, nil
+		}
+	}
+	return models.OAuthState{}, ErrNotFound
+}
+
+func (r *memoryOAuthStates) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	defer r.s.lock()()
+	data := *r.s.data
+	kept := data.oauthStates[:0:0]
+	for _, state := range data.oauthStates {
+		if !state.ExpiresAt.Before(now) {
+			kept = append(kept, state)
+		}
+	}
+	deleted := int64(len(data.oauthStates) - len(kept))
+	data.oauthStates = kept
+	return deleted, nil
+}
+
+type memoryAchievements struct {
+	s *memoryStore
+}
+
+// Evaluate never awards anything: the rules read submissions and battles, which the
+// memory store does not hold
+func (r *memoryAchievements) Evaluate(ctx context.Context, userID uint, event achievements.Event) ([]models.UserAchievement, error) {
+	return nil, nil
+}
+
+func (r *memoryAchievements) TakeUnannounced(ctx context.Context, userID uint) ([]models.UserAchievement, error) {
+	defer r.s.lock()()
+	var pending []models.UserAchievement
+	for i, achievement := range (*r.s.data).achievements {
+		if achievement.UserID == userID && !achievement.Announced {
+			(*r.s.data).achievements[i].Announced = true
+			pending = append(pending, achievement)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryAchievements) ListEarned(ctx context.Context, userID uint) ([]models.UserAchievement, error) {
+	defer r.s.lock()()
+	var earned []models.UserAchievement
+	for _, achievement := range (*r.s.data).achievements {
+		if achievement.UserID == userID {
+			earned = append(earned, achievement)
+		}
+	}
+	return earned, nil
+}
+
+type memoryExports struct {
+	s *memoryStore
+}
+
+// UserRecords returns the user's sessions; the memory store holds none of the other records
+func (r *memoryExports) UserRecords(ctx context.Context, userID uint) (UserRecords, error) {
+	defer r.s.lock()()
+	var records UserRecords
+	for _, session := range (*r.s.data).sessions {
+		if session.UserID == userID {
+			records.Sessions = append(records.Sessions, session)
+		}
+	}
+	return records, nil
+}
+
+type memoryQuestions struct {
+	s *memoryStore
+}
+
+func (r *memoryQuestions) Create(ctx context.Context, question *models.Question) error {
+	defer r.s.lock()()
+	data := *r.s.data
+
+	for _, existing := range data.questions {
+		if existing.QuestionID == question.QuestionID {
+			return ErrConflict
+		}
+	}
+	now := time.Now()
+	question.ID = data.id()
+	question.CreatedAt = now
+	question.UpdatedAt = now
+	data.questions = append(data.questions, *question)
+	return nil
+}
+
+func (r *memoryQuestions) FindByQuestionID(ctx context.Context, questionID string) (models.Question, error) {
+	defer r.s.lock()()
+	for _, question := range (*r.s.data).questions {
+		if question.QuestionID == questionID && !question.DeletedAt.Valid {
+			return question, nil
+		}
+	}
+	return models.Question{}, ErrNotFound
+}
+
+func (r *memoryQuestions) List(ctx context.Context, category string) ([]models.Question, error) {
+	defer r.s.lock()()
+	questions := []models.Question{}
+	for _, question := range (*r.s.data).questions {
+		if !question.DeletedAt.Valid && (category == "" || question.Category == category) {
+			questions = append(questions, question)
+		}
+	}
+	return questions, nil
+}
+
+type memoryAudit struct {
+	s *memoryStore
+}
+
+func (r *memoryAudit) Append(ctx context.Context, entry *models.AuditLog) error {
+	defer r.s.lock()()
+	data := *r.s.data
+	entry.ID = data.id()
+	entry.CreatedAt = time.Now()
+	data.audit = append(data.audit, *entry)
+	return nil
+}
@@ -0,0 +1,536 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore returns a Store backed by the given GORM connection or transaction
+func NewPostgresStore(db *gorm.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Users() UserRepository {
+	return &postgresUsers{db: s.db}
+}
+
+func (s *postgresStore) Sessions() SessionRepository {
+	return &postgresSessions{db: s.db}
+}
+
+func (s *postgresStore) OAuthStates() OAuthStateRepository {
+	return &postgresOAuthStates{db: s.db}
+}
+
+func (s *postgresStore) Achievements() AchievementRepository {
+	return &postgresAchievements{db: s.db}
+}
+
+func (s *postgresStore) Exports() ExportRepository {
+	return &postgresExports{db: s.db}
+}
+
+func (s *postgresStore) Questions() QuestionRepository {
+	return &postgresQuestions{db: s.db}
+}
+
+func (s *postgresStore) Audit() AuditRepository {
+	return NewPostgresAudit(s.db)
+}
+
+// Transaction nests as a savepoint when the store already wraps a transaction
+func (s *postgresStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&postgresStore{db: tx})
+	})
+}
+
+// notFound maps GORM's missing-row error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type postgresUsers struct {
+	db *gorm.DB
+}
+
+func (r *postgresUsers) FindByID(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	return user, notFound(err)
+}
+
+func (r *postgresUsers) FindByUID(ctx context.Context, uid string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("firebase_uid = ?", uid).First(&user).Error
+	return user, notFound(err)
+}
+
+func (r *postgresUsers) Create(ctx context.Context, user *models.User) error {
+	err := r.db.WithContext(ctx).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *postgresUsers) Provision(ctx context.Context, uid string, claims map[string]interface{}) (models.User, bool, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("firebase_uid = ?", uid).First(&user).Error
+	if err == nil && user.DeletedAt.Valid {
+		return models.User{}, false, ErrAccountDeleted
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, false, err
+	}
+
+	user, err = userFromClaims(uid, claims)
+	if err != nil {
+		return user, false, err
+	}
+	email := user.Email
+	// Racing inserts are absorbed by the unique constraint and every caller reads back the same row
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&user)
+	if result.Error != nil {
+		return user, false, result.Error
+	}
+	created := result.RowsAffected == 1
+
+	user, err = r.FindByUID(ctx, uid)
+	if errors.Is(err, ErrNotFound) {
+		// The insert was skipped for another reason, such as the email belonging to a different UID
+		return user, false, fmt.Errorf("cannot provision user %s: email %s is already in use", uid, email)
+	}
+	return user, created, err
+}
+
+func (r *postgresUsers) UpdateProfile(ctx context.Context, id uint, update ProfileUpdate) (models.User, error) {
+	if columns := update.columns(); len(columns) > 0 {
+		var values models.User
+		update.apply(&values)
+		// Select writes exactly these columns, including zero values such as an empty bio
+		result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Select(columns).Updates(&values)
+		if result.Error != nil {
+			return models.User{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.User{}, ErrNotFound
+		}
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *postgresUsers) DeleteAccount(ctx context.Context, id uint, purge bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		personal := []struct {
+			model interface{}
+			query string
+		}{
+			{&models.UserSession{}, "user_id = @id"},
+			{&models.EmailVerification{}, "user_id = @id"},
+			{&models.UserAchievement{}, "user_id = @id"},
+			{&models.UserRole{}, "user_id = @id"},
+			{&models.Friendship{}, "requester_id = @id OR addressee_id = @id"},
+			{&models.UserBlock{}, "blocker_id = @id OR blocked_id = @id"},
+		}
+		if purge {
+			personal = append(personal, []struct {
+				model interface{}
+				query string
+			}{
+				{&models.Submission{}, "user_id = @id"},
+				{&models.ContestRegistration{}, "user_id = @id"},
+				{&models.RoomParticipant{}, "user_id = @id"},
+			}...)
+		}
+
+		for _, p := range personal {
+			if err := tx.Where(p.query, map[string]interface{}{"id": id}).Delete(p.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Both paths skip soft-deleted rows and report a missing user as ErrNotFound,
+		// which also rolls back the deletes above
+		if purge {
+			result := tx.Unscoped().Where("deleted_at IS NULL").Delete(&models.User{}, id)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+			return nil
+		}
+
+		// The email gets a placeholder so the address can be reused. The UID is kept so the
+		// soft-deleted row keeps its tokens from provisioning a fresh account.
+		result := tx.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"email":          fmt.Sprintf("deleted-%d@deleted.invalid", id),
+			"email_verified": false,
+			"is_admin":       false,
+			"display_name":   "Deleted user",
+			"photo_url":      "",
+			"phone":          "",
+			"country":        "",
+			"bio":            "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+}
+
+func (r *postgresUsers) SetEmailVerified(ctx context.Context, id uint, verified bool) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("email_verified", verified).Error
+}
+
+func (r *postgresUsers) Suspend(ctx context.Context, id uint, reason string, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND suspended_at IS NULL", id).
+			Updates(map[string]interface{}{"suspended_at": at, "suspension_reason": reason})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadySuspended
+		}
+		return tx.Model(&models.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+}
+
+func (r *postgresUsers) Unsuspend(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND suspended_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"suspended_at": nil, "suspension_reason": ""})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotSuspended
+	}
+	return nil
+}
+
+func (r *postgresUsers) List(ctx context.Context, filter UserFilter, page, pageSize int) ([]models.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Email != "" {
+		query = query.Where("email ILIKE ?", "%"+escapeLike(filter.Email)+"%")
+	}
+	if filter.Country != "" {
+		query = query.Where("LOWER(country) = LOWER(?)", filter.Country)
+	}
+	// Everyone is a player, so that filter matches all users
+	if filter.Role != "" && filter.Role != rbac.RolePlayer {
+		query = query.Where("id IN (?)", r.db.Model(&models.UserRole{}).Select("user_id").Where("role = ?", string(filter.Role)))
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query = query.Where("suspended_at IS NOT NULL")
+		} else {
+			query = query.Where("suspended_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&users).Error
+	return users, total, err
+}
+
+func (r *postgresUsers) Roles(ctx context.Context, id uint) ([]rbac.Role, error) {
+	return rbac.RolesOf(r.db.WithContext(ctx), id)
+}
+
+func (r *postgresUsers) RolesByUser(ctx context.Context, ids []uint) (map[uint][]rbac.Role, error) {
+	var grants []models.UserRole
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", ids).Order("role").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make(map[uint][]rbac.Role, len(ids))
+	for _, id := range ids {
+		roles[id] = []rbac.Role{rbac.RolePlayer}
+	}
+	for _, grant := range grants {
+		roles[grant.UserID] = append(roles[grant.UserID], rbac.Role(grant.Role))
+	}
+	return roles, nil
+}
+
+func (r *postgresUsers) GrantRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error {
+	return rbac.Grant(r.db.WithContext(ctx), actorID, targetID, role)
+}
+
+func (r *postgresUsers) RevokeRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error {
+	return rbac.Revoke(r.db.WithContext(ctx), actorID, targetID, role)
+}
+
+func (r *postgresUsers) RevokeAllRoles(ctx context.Context, actorID, targetID uint) ([]rbac.Role, error) {
+	return rbac.RevokeAll(r.db.WithContext(ctx), actorID, targetID)
+}
+
+func (r *postgresUsers) RoleChanges(ctx context.Context, targetID uint, limit int) ([]models.RoleChange, error) {
+	query := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if targetID != 0 {
+		query = query.Where("target_id = ?", targetID)
+	}
+
+	var changes []models.RoleChange
+	err := query.Find(&changes).Error
+	return changes, err
+}
+
+func (r *postgresUsers) ReplaceEmailVerification(ctx context.Context, verification *models.EmailVerification, cooldown time.Duration) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialize per user so the cooldown cannot be bypassed by parallel requests
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.User{}, verification.UserID).Error; err != nil {
+			return notFound(err)
+		}
+
+		var previous models.EmailVerification
+		if err := tx.Where("user_id = ?", verification.UserID).Order("created_at DESC").First(&previous).Error; err == nil &&
+			time.Since(previous.CreatedAt) < cooldown {
+			return ErrCooldown
+		}
+
+		if err := tx.Where("user_id = ?", verification.UserID).Delete(&models.EmailVerification{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(verification).Error
+	})
+}
+
+func (r *postgresUsers) ConsumeEmailVerification(ctx context.Context, tokenHash string) (models.EmailVerification, error) {
+	// Deleting with RETURNING makes the token single-use
+	var verification models.EmailVerification
+	result := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("token_hash = ?", tokenHash).
+		Delete(&verification)
+	if result.Error != nil {
+		return verification, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return verification, ErrNotFound
+	}
+	return verification, nil
+}
+
+type postgresSessions struct {
+	db *gorm.DB
+}
+
+func (r *postgresSessions) Create(ctx context.Context, session *models.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *postgresSessions) FindActiveByTokenHash(ctx context.Context, tokenHash string) (models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.WithContext(ctx).Where("refresh_token_hash = ? AND revoked_at IS NULL", tokenHash).First(&session).Error
+	return session, notFound(err)
+}
+
+func (r *postgresSessions) FindActive(ctx context.Context, userID uint, sessionID string) (models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("session_id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		First(&session).Error
+	return session, notFound(err)
+}
+
+func (r *postgresSessions) ListActive(ctx context.Context, userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *postgresSessions) Rotate(ctx context.Context, id uint, oldHash string, rotation SessionRotation) error {
+	// The old-hash condition stops a concurrent refresh, or a revocation in between,
+	// from being silently overwritten
+	result := r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": rotation.RefreshTokenHash,
+			"user_agent":         rotation.UserAgent,
+			"ip_address":         rotation.IPAddress,
+			"last_used_at":       rotation.LastUsedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresSessions) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *postgresSessions) RevokeAll(ctx context.Context, userID uint, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *postgresSessions) DeleteRevoked(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("revoked_at < ?", before).Delete(&models.UserSession{})
+	return result.RowsAffected, result.Error
+}
+
+type postgresOAuthStates struct {
+	db *gorm.DB
+}
+
+func (r *postgresOAuthStates) Create(ctx context.Context, state *models.OAuthState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+func (r *postgresOAuthStates) Consume(ctx context.Context, stateHash string) (models.OAuthState, error) {
+	// Deleting with RETURNING makes the state single-use
+	var state models.OAuthState
+	result := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&state)
+	if result.Error != nil {
+		return state, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return state, ErrNotFound
+	}
+	return state, nil
+}
+
+func (r *postgresOAuthStates) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&models.OAuthState{})
+	return result.RowsAffected, result.Error
+}
+
+type postgresAchievements struct {
+	db *gorm.DB
+}
+
+func (r *postgresAchievements) Evaluate(ctx context.Context, userID uint, event achievements.Event) ([]models.UserAchievement, error) {
+	return achievements.Evaluate(r.db.WithContext(ctx), userID, event)
+}
+
+func (r *postgresAchievements) TakeUnannounced(ctx context.Context, userID uint) ([]models.UserAchievement, error) {
+	return achievements.TakeUnannounced(r.db.WithContext(ctx), userID)
+}
+
+func (r *postgresAchievements) ListEarned(ctx context.Context, userID uint) ([]models.UserAchievement, error) {
+	return achievements.ListEarned(r.db.WithContext(ctx), userID)
+}
+
+type postgresExports struct {
+	db *gorm.DB
+}
+
+func (r *postgresExports) UserRecords(ctx context.Context, userID uint) (UserRecords, error) {
+	var records UserRecords
+	queries := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&records.Submissions, "user_id = @id"},
+		{&records.Registrations, "user_id = @id"},
+		{&records.Participants, "user_id = @id"},
+		{&records.Friendships, "requester_id = @id OR addressee_id = @id"},
+		{&records.Blocks, "blocker_id = @id"},
+		{&records.Sessions, "user_id = @id"},
+	}
+	for _, q := range queries {
+		err := r.db.WithContext(ctx).Where(q.query, map[string]interface{}{"id": userID}).Order("created_at").Find(q.dest).Error
+		if err != nil {
+			return UserRecords{}, err
+		}
+	}
+	return records, nil
+}
+
+type postgresQuestions struct {
+	db *gorm.DB
+}
+
+func (r *postgresQuestions) Create(ctx context.Context, question *models.Question) error {
+	return r.db.WithContext(ctx).Create(question).Error
+}
+
+func (r *postgresQuestions) FindByQuestionID(ctx context.Context, questionID string) (models.Question, error) {
+	var question models.Question
+	err := r.db.WithContext(ctx).Where("question_id = ?", questionID).First(&question).Error
+	return question, notFound(err)
+}
+
+func (r *postgresQuestions) List(ctx context.Context, category string) ([]models.Question, error) {
+	query := r.db.WithContext(ctx)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var questions []models.Question
+	err := query.Find(&questions).Error
+	return questions, err
+}
+
+type postgresAudit struct {
+	db *gorm.DB
+}
+
+// NewPostgresAudit returns an AuditRepository writing through db. Code still working
+// directly with GORM passes its transaction so entries commit with the change.
+func NewPostgresAudit(db *gorm.DB) AuditRepository {
+	return &postgresAudit{db: db}
+}
+
+func (r *postgresAudit) Append(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// escapeLike escapes the LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/achievements"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+)
+
+var (
+	ErrNotFound = errors.New("record not found")
+	ErrConflict = errors.New("record already exists")
+	// ErrCannotProvision is returned when a token lacks the claims needed to create a user
+	ErrCannotProvision = errors.New("token has no email claim to provision a user from")
+	// ErrAccountDeleted is returned by Provision for the UID of a deleted account
+	ErrAccountDeleted   = errors.New("account has been deleted")
+	ErrAlreadySuspended = errors.New("user is already suspended")
+	ErrNotSuspended     = errors.New("user is not suspended")
+	// ErrCooldown is returned when a verification email was issued too recently
+	ErrCooldown = errors.New("a verification email was sent recently")
+)
+
+// Store gives access to every repository. Controllers receive one at construction
+// instead of reaching for database.DB, so tests can substitute NewMemoryStore.
+type Store interface {
+	Users() UserRepository
+	Sessions() SessionRepository
+	OAuthStates() OAuthStateRepository
+	Achievements() AchievementRepository
+	Exports() ExportRepository
+	Questions() QuestionRepository
+	Audit() AuditRepository
+	// Transaction runs fn against a Store whose changes commit together.
+	// Returning an error from fn rolls all of them back.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+}
+
+// UserRepository stores users, their roles and their pending email verifications
+type UserRepository interface {
+	FindByID(ctx context.Context, id uint) (models.User, error)
+	FindByUID(ctx context.Context, uid string) (models.User, error)
+	// Create inserts a user, returning ErrConflict if the UID or email is taken
+	Create(ctx context.Context, user *models.User) error
+	// Provision returns the user for an identity provider UID, creating it from the
+	// verified token claims (email, name, picture, email_verified) when it does not exist.
+	// created reports whether this call inserted the row. It returns ErrAccountDeleted
+	// rather than recreating a deleted account, and is idempotent and safe under
+	// concurrent calls.
+	Provision(ctx context.Context, uid string, claims map[string]interface{}) (user models.User, created bool, err error)
+	// UpdateProfile writes only the fields set in update, leaving the rest of the row as
+	// it is in the database, and returns the updated user
+	UpdateProfile(ctx context.Context, id uint, update ProfileUpdate) (models.User, error)
+	SetEmailVerified(ctx context.Context, id uint, verified bool) error
+	// Suspend marks the user suspended and revokes their sessions,
+	// returning ErrAlreadySuspended if they already are
+	Suspend(ctx context.Context, id uint, reason string, at time.Time) error
+	// Unsuspend lifts a suspension, returning ErrNotSuspended if there is none
+	Unsuspend(ctx context.Context, id uint) error
+	// DeleteAccount removes the user's personal data: sessions, verifications, roles,
+	// achievements and social links. With purge the row and every record referencing it
+	// are deleted; otherwise the row is scrubbed and soft-deleted, keeping competition
+	// records, and its UID stays reserved so Provision refuses it. A missing or already
+	// deleted user is ErrNotFound.
+	DeleteAccount(ctx context.Context, id uint, purge bool) error
+	// List returns one page of users matching the filter, newest first, and the total match count
+	List(ctx context.Context, filter UserFilter, page, pageSize int) ([]models.User, int64, error)
+
+	// Roles returns the user's roles, including the implicit player role
+	Roles(ctx context.Context, id uint) ([]rbac.Role, error)
+	// RolesByUser returns the roles of several users at once
+	RolesByUser(ctx context.Context, ids []uint) (map[uint][]rbac.Role, error)
+	// GrantRole and RevokeRole follow rbac.Grant and rbac.Revoke, including their errors
+	GrantRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error
+	RevokeRole(ctx context.Context, actorID, targetID uint, role rbac.Role) error
+	// RevokeAllRoles leaves the target with only the player role and returns what was removed
+	RevokeAllRoles(ctx context.Context, actorID, targetID uint) ([]rbac.Role, error)
+	// RoleChanges returns up to limit role changes, newest first. A targetID of 0 means every user.
+	RoleChanges(ctx context.Context, targetID uint, limit int) ([]models.RoleChange, error)
+
+	// ReplaceEmailVerification stores a new verification token for the user, discarding
+	// older ones. It returns ErrCooldown if the previous one is younger than cooldown.
+	ReplaceEmailVerification(ctx context.Context, verification *models.EmailVerification, cooldown time.Duration) error
+	// ConsumeEmailVerification deletes and returns the verification with the token hash,
+	// returning ErrNotFound if there is none. Each token can be consumed once.
+	ConsumeEmailVerification(ctx context.Context, tokenHash string) (models.EmailVerification, error)
+}
+
+// UserFilter narrows List. Zero values match everything.
+type UserFilter struct {
+	Email         string // case-insensitive substring
+	Country       string // case-insensitive
+	Role          rbac.Role
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Suspended     *bool
+}
+
+// ProfileUpdate holds the user fields UpdateProfile may change. Nil fields are left alone.
+type ProfileUpdate struct {
+	DisplayName   *string
+	PhotoURL      *string
+	Phone         *string
+	Country       *string
+	Bio           *string
+	EmailVerified *bool
+
+	EmailVisibility        *models.Visibility
+	PhoneVisibility        *models.Visibility
+	CountryVisibility      *models.Visibility
+	BioVisibility          *models.Visibility
+	RatingVisibility       *models.Visibility
+	AchievementsVisibility *models.Visibility
+	StatsVisibility        *models.Visibility
+}
+
+// columns names the columns of the set fields
+func (u ProfileUpdate) columns() []string {
+	var columns []string
+	for column, set := range map[string]bool{
+		"display_name":            u.DisplayName != nil,
+		"photo_url":               u.PhotoURL != nil,
+		"phone":                   u.Phone != nil,
+		"country":                 u.Country != nil,
+		"bio":                     u.Bio != nil,
+		"email_verified":          u.EmailVerified != nil,
+		"email_visibility":        u.EmailVisibility != nil,
+		"phone_visibility":        u.PhoneVisibility != nil,
+		"country_visibility":      u.CountryVisibility != nil,
+		"bio_visibility":          u.BioVisibility != nil,
+		"rating_visibility":       u.RatingVisibility != nil,
+		"achievements_visibility": u.AchievementsVisibility != nil,
+		"stats_visibility":        u.StatsVisibility != nil,
+	} {
+		if set {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// apply sets the fields of update on user
+func (u ProfileUpdate) apply(user *models.User) {
+	setString := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setVisibility := func(field *models.Visibility, value *models.Visibility) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setString(&user.DisplayName, u.DisplayName)
+	setString(&user.PhotoURL, u.PhotoURL)
+	setString(&user.Phone, u.Phone)
+	setString(&user.Country, u.Country)
+	setString(&user.Bio, u.Bio)
+	if u.EmailVerified != nil {
+		user.EmailVerified = *u.EmailVerified
+	}
+	setVisibility(&user.EmailVisibility, u.EmailVisibility)
+	setVisibility(&user.PhoneVisibility, u.PhoneVisibility)
+	setVisibility(&user.CountryVisibility, u.CountryVisibility)
+	setVisibility(&user.BioVisibility, u.BioVisibility)
+	setVisibility(&user.RatingVisibility, u.RatingVisibility)
+	setVisibility(&user.AchievementsVisibility, u.AchievementsVisibility)
+	setVisibility(&user.StatsVisibility, u.StatsVisibility)
+}
+
+// SessionRepository stores signed-in devices, one per refresh token
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.UserSession) error
+	// FindActiveByTokenHash returns the unrevoked session holding the refresh token hash,
+	// or ErrNotFound
+	FindActiveByTokenHash(ctx context.Context, tokenHash string) (models.UserSession, error)
+	// FindActive returns the user's unrevoked session with the public session ID, or ErrNotFound
+	FindActive(ctx context.Context, userID uint, sessionID string) (models.UserSession, error)
+	// ListActive returns the user's unrevoked sessions, most recently used first
+	ListActive(ctx context.Context, userID uint) ([]models.UserSession, error)
+	// Rotate stores the session's new refresh token hash and device details. It returns
+	// ErrNotFound if the session was revoked or rotated since oldHash was read.
+	Rotate(ctx context.Context, id uint, oldHash string, rotation SessionRotation) error
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	// RevokeAll revokes every unrevoked session of the user and returns how many there were
+	RevokeAll(ctx context.Context, userID uint, at time.Time) (int64, error)
+	// DeleteRevoked removes sessions revoked before the given time and returns how many there were
+	DeleteRevoked(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SessionRotation holds what a refresh changes on a session
+type SessionRotation struct {
+	RefreshTokenHash string
+	UserAgent        string
+	IPAddress        string
+	LastUsedAt       time.Time
+}
+
+// OAuthStateRepository stores the state of Google sign-in flows in progress
+type OAuthStateRepository interface {
+	Create(ctx context.Context, state *models.OAuthState) error
+	// Consume deletes and returns the state with the hash, returning ErrNotFound if there
+	// is none. Each state can be consumed once.
+	Consume(ctx context.Context, stateHash string) (models.OAuthState, error)
+	// DeleteExpired removes states that expired before now and returns how many there were
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AchievementRepository stores the achievements users have earned
+type AchievementRepository interface {
+	// Evaluate stores the achievements the event newly satisfies for the user, following
+	// achievements.Evaluate, and returns them
+	Evaluate(ctx context.Context, userID uint, event achievements.Event) ([]models.UserAchievement, error)
+	// TakeUnannounced returns the user's achievements not yet announced and marks them announced
+	TakeUnannounced(ctx context.Context, userID uint) ([]models.UserAchievement, error)
+	// ListEarned returns the user's achievements, oldest first
+	ListEarned(ctx context.Context, userID uint) ([]models.UserAchievement, error)
+}
+
+// ExportRepository reads the records included in a user's data export
+type ExportRepository interface {
+	// UserRecords returns every record that belongs to the user, each kind oldest first
+	UserRecords(ctx context.Context, userID uint) (UserRecords, error)
+}
+
+// UserRecords holds what a data export reads besides the user row, roles and achievements
+type UserRecords struct {
+	Submissions   []models.Submission
+	Registrations []models.ContestRegistration
+	Participants  []models.RoomParticipant
+	Friendships   []models.Friendship
+	Blocks        []models.UserBlock // blocks the user placed
+	Sessions      []models.UserSession
+}
+
+// QuestionRepository stores questions
+type QuestionRepository interface {
+	Create(ctx context.Context, question *models.Question) error
+	// FindByQuestionID looks a question up by its public ID
+	FindByQuestionID(ctx context.Context, questionID string) (models.Question, error)
+	// List returns questions in creation order, limited to a category when one is given
+	List(ctx context.Context, category string) ([]models.Question, error)
+}
+
+// AuditRepository appends to the admin audit log
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditLog) error
+}
+
+// userFromClaims builds a new user from verified token claims
+func userFromClaims(uid string, claims map[string]interface{}) (models.User, error) {
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return models.User{}, ErrCannotProvision
+	}
+	name, _ := claims["name"].(string)
+	picture, _ := claims["picture"].(string)
+	verified, _ := claims["email_verified"].(bool)
+
+	return models.User{
+		FirebaseUID:   uid,
+		Email:         email,
+		EmailVerified: verified,
+		DisplayName:   name,
+		PhotoURL:      picture,
+	}, nil
+}
@@ -0,0 +1,580 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/logging"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/metrics"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/models"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var verificationLink = regexp.MustCompile(`/api/v1/auth/verify-email\?token=(\S+)`)
+
+func TestSignUpAndVerifyEmail(t *testing.T) {
+	h := newHarness(t)
+	uid := h.signUp("player@example.com", "player-password")
+
+	messages := h.mailer.Messages()
+	if len(messages) != 1 || messages[0].To != "player@example.com" {
+		t.Fatalf("expected one verification email to the new user, got %+v", messages)
+	}
+	match := verificationLink.FindStringSubmatch(messages[0].Body)
+	if match == nil {
+		t.Fatalf("verification email has no link: %q", messages[0].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("decoding verification token: %v", err)
+	}
+
+	// Opening the link only shows the confirmation form, so link scanners cannot use it up
+	for i := 0; i < 2; i++ {
+		resp := h.do(http.MethodGet, "/api/v1/auth/verify-email?token="+url.QueryEscape(token), "", nil)
+		h.expect(resp, http.StatusOK)
+		if !strings.Contains(resp.Text, `method="post"`) || resp.Header.Get("Cache-Control") != "no-store" {
+			t.Fatalf("expected an uncached confirmation form, got %q", resp.Text)
+		}
+	}
+	unverified, err := h.store.Users().FindByUID(context.Background(), uid)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	if unverified.EmailVerified {
+		t.Fatal("opening the link verified the email before it was confirmed")
+	}
+
+	// Submitting the form consumes the token
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := h.send(req)
+	h.expect(resp, http.StatusOK)
+	if !strings.Contains(resp.Text, "verified") {
+		t.Errorf("expected a confirmation page, got %q", resp.Text)
+	}
+	// Links are single-use
+	h.expect(h.do(http.MethodPost, "/api/v1/auth/verify-email", "", gin.H{"token": token}), http.StatusBadRequest)
+
+	user, err := h.store.Users().FindByUID(context.Background(), uid)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Error("expected the stored user to be verified")
+	}
+	record, err := h.provider.GetUser(context.Background(), uid)
+	if err != nil {
+		t.Fatalf("loading provider record: %v", err)
+	}
+	if !record.EmailVerified {
+		t.Error("expected the identity provider record to be verified")
+	}
+
+	resp = h.do(http.MethodGet, "/api/v1/verify", h.token(uid), nil)
+	h.expect(resp, http.StatusOK)
+
+	// The address is taken now
+	resp = h.do(http.MethodPost, "/api/v1/auth/signup", "", gin.H{"email": "player@example.com", "password": "another-password"})
+	h.expect(resp, http.StatusBadRequest)
+}
+
+func TestSignUpValidation(t *testing.T) {
+	h := newHarness(t)
+
+	resp := h.do(http.MethodPost, "/api/v1/auth/signup", "", gin.H{"email": "not-an-email", "password": "player-password"})
+	h.expect(resp, http.StatusBadRequest)
+	resp = h.do(http.MethodPost, "/api/v1/auth/signup", "", gin.H{"email": "short@example.com", "password": "short"})
+	h.expect(resp, http.StatusBadRequest)
+	if n := len(h.mailer.Messages()); n != 0 {
+		t.Errorf("expected no emails for rejected signups, got %d", n)
+	}
+}
+
+func TestProvisioning(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	// An account created directly with the identity provider gets a row on first use
+	record, err := h.provider.CreateUser(ctx, identity.UserToCreate{Email: "outside@example.com", Password: "outside-password"})
+	if err != nil {
+		t.Fatalf("creating provider account: %v", err)
+	}
+	provisioned := testutil.ToFloat64(metrics.Signups.WithLabelValues("provisioned"))
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", h.token(record.UID), nil), http.StatusOK)
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", h.token(record.UID), nil), http.StatusOK)
+	if got := testutil.ToFloat64(metrics.Signups.WithLabelValues("provisioned")) - provisioned; got != 1 {
+		t.Errorf("expected one provisioned signup, got %v", got)
+	}
+
+	// Revoked tokens and tokens of disabled or deleted accounts are refused
+	stale := h.token(record.UID)
+	// Revocation has one-second resolution, as with Firebase, so wait for the next second
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	if err := h.provider.RevokeRefreshTokens(ctx, record.UID); err != nil {
+		t.Fatal(err)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", stale, nil), http.StatusUnauthorized)
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", h.token(record.UID), nil), http.StatusOK)
+
+	if err := h.provider.SetDisabled(ctx, record.UID, true); err != nil {
+		t.Fatal(err)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", h.token(record.UID), nil), http.StatusForbidden)
+
+	fresh := h.token(record.UID)
+	if err := h.provider.DeleteUser(ctx, record.UID); err != nil {
+		t.Fatal(err)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", fresh, nil), http.StatusUnauthorized)
+}
+
+func TestProfileUpdatesAndDeletion(t *testing.T) {
+	h := newHarness(t)
+	uid := h.signUp("player@example.com", "player-password")
+	ctx := context.Background()
+
+	// Each update writes only its own fields
+	h.expect(h.do(http.MethodPut, "/api/v1/users/profile", h.token(uid), gin.H{"bio": "Hello", "country": "NZ"}), http.StatusOK)
+	h.expect(h.do(http.MethodPut, "/api/v1/users/privacy", h.token(uid), gin.H{"stats": "friends"}), http.StatusOK)
+	h.expect(h.do(http.MethodPut, "/api/v1/users/profile", h.token(uid), gin.H{"bio": ""}), http.StatusOK)
+	user, err := h.store.Users().FindByUID(ctx, uid)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	if user.Bio != "" || user.Country != "NZ" || user.StatsVisibility != models.VisibilityFriends {
+		t.Errorf("expected bio cleared, country kept and stats private to friends, got %q, %q, %q",
+			user.Bio, user.Country, user.StatsVisibility)
+	}
+
+	token := h.token(uid)
+	resp := h.do(http.MethodDelete, "/api/v1/users/me", token, nil)
+	h.expect(resp, http.StatusOK)
+	if _, err := h.provider.GetUser(ctx, uid); !errors.Is(err, identity.ErrUserNotFound) {
+		t.Errorf("expected the identity account to be deleted, got %v", err)
+	}
+	if _, err := h.store.Users().FindByUID(ctx, uid); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the user to be deleted, got %v", err)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", token, nil), http.StatusUnauthorized)
+
+	// The address can be used for a new account
+	h.signUp("player@example.com", "player-password")
+}
+
+func TestSessions(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	uid := h.signUp("player@example.com", "player-password")
+	signIn := func() (string, string) {
+		resp := h.do(http.MethodPost, "/api/v1/auth/signin", "", gin.H{"email": "player@example.com", "password": "player-password"})
+		h.expect(resp, http.StatusOK)
+		refreshToken, _ := resp.Body["refreshToken"].(string)
+		sessionID, _ := resp.Body["sessionId"].(string)
+		return refreshToken, sessionID
+	}
+
+	laptopRefresh, laptopID := signIn()
+	phoneRefresh, _ := signIn()
+	resp := h.do(http.MethodGet, "/api/v1/auth/sessions", h.token(uid), nil)
+	h.expect(resp, http.StatusOK)
+	if sessions, _ := resp.Body["sessions"].([]interface{}); len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %v", resp.Body["sessions"])
+	}
+
+	// Refreshing rotates the token, so the old one stops working
+	resp = h.do(http.MethodPost, "/api/v1/auth/refresh", "", gin.H{"refreshToken": laptopRefresh})
+	h.expect(resp, http.StatusOK)
+	if resp.Body["sessionId"] != laptopID {
+		t.Errorf("expected session %s to be refreshed, got %v", laptopID, resp.Body["sessionId"])
+	}
+	h.expect(h.do(http.MethodPost, "/api/v1/auth/refresh", "", gin.H{"refreshToken": laptopRefresh}), http.StatusUnauthorized)
+
+	// The provider revokes every refresh token at once, so revoking one session revokes all
+	h.expect(h.do(http.MethodDelete, "/api/v1/auth/sessions/unknown", h.token(uid), nil), http.StatusNotFound)
+	resp = h.do(http.MethodDelete, "/api/v1/auth/sessions/"+laptopID, h.token(uid), nil)
+	h.expect(resp, http.StatusOK)
+	if resp.Body["revoked"] != float64(2) {
+		t.Errorf("expected 2 sessions revoked, got %v", resp.Body["revoked"])
+	}
+	h.expect(h.do(http.MethodPost, "/api/v1/auth/refresh", "", gin.H{"refreshToken": phoneRefresh}), http.StatusUnauthorized)
+
+	// Suspension revokes the account's sessions
+	refreshToken, _ := signIn()
+	user, err := h.store.Users().FindByUID(context.Background(), uid)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	h.expect(h.do(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/suspend", user.ID), h.token(adminUID), gin.H{"reason": "spam"}), http.StatusOK)
+	h.expect(h.do(http.MethodPost, "/api/v1/auth/refresh", "", gin.H{"refreshToken": refreshToken}), http.StatusUnauthorized)
+}
+
+func TestAdminPromotion(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	playerUID := h.signUp("player@example.com", "player-password")
+
+	// Players cannot manage roles, including promoting themselves
+	h.expect(h.do(http.MethodGet, "/api/v1/admin/roles", h.token(playerUID), nil), http.StatusForbidden)
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/users/make-admin", h.token(playerUID), gin.H{"userId": playerUID}), http.StatusForbidden)
+
+	resp := h.do(http.MethodPost, "/api/v1/admin/users/make-admin", h.token(adminUID), gin.H{"userId": playerUID})
+	h.expect(resp, http.StatusOK)
+	if synced, _ := resp.Body["claimsSynced"].(bool); !synced {
+		t.Errorf("expected claims to be synced: %v", resp.Body)
+	}
+
+	// A fresh token carries the new role claim
+	record, err := h.provider.GetUser(context.Background(), playerUID)
+	if err != nil {
+		t.Fatalf("loading provider record: %v", err)
+	}
+	if roles, _ := rbac.RolesFromClaims(record.CustomClaims); !containsRole(roles, rbac.RoleAdmin) {
+		t.Errorf("expected admin in custom claims, got %v", record.CustomClaims)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/admin/roles", h.token(playerUID), nil), http.StatusOK)
+
+	user, err := h.store.Users().FindByUID(context.Background(), playerUID)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	if !user.IsAdmin {
+		t.Error("expected the promoted user to be an admin")
+	}
+
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/users/make-admin", h.token(adminUID), gin.H{"userId": "missing"}), http.StatusNotFound)
+}
+
+func TestRoleRevocation(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	authorUID := h.signUp("author@example.com", "author-password")
+
+	ctx := context.Background()
+	author, err := h.store.Users().FindByUID(ctx, authorUID)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	rolesPath := fmt.Sprintf("/api/v1/admin/users/%d/roles", author.ID)
+	h.expect(h.do(http.MethodPost, rolesPath, h.token(adminUID), gin.H{"role": "question-author"}), http.StatusOK)
+
+	// A session started while the role was held carries it in its claims
+	session, err := h.provider.SignIn(ctx, "author@example.com", "author-password")
+	if err != nil {
+		t.Fatalf("signing in: %v", err)
+	}
+	invalid := gin.H{"category": "algebra", "difficulty": "trivial"}
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/questions/generate", session.IDToken, invalid), http.StatusBadRequest)
+
+	// Revocation has one-second resolution, so revoke in the next second
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	h.expect(h.do(http.MethodDelete, rolesPath+"/question-author", h.token(adminUID), nil), http.StatusOK)
+
+	// The token carrying the stale claim is revoked and its session cannot be renewed
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/questions/generate", session.IDToken, invalid), http.StatusUnauthorized)
+	if _, err := h.provider.RefreshIDToken(ctx, session.RefreshToken); err == nil {
+		t.Error("expected the refresh token to be revoked with the role")
+	}
+	// A new token carries the updated claim
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(authorUID), invalid), http.StatusForbidden)
+	record, err := h.provider.GetUser(ctx, authorUID)
+	if err != nil {
+		t.Fatalf("loading provider record: %v", err)
+	}
+	if roles, _ := rbac.RolesFromClaims(record.CustomClaims); containsRole(roles, rbac.RoleQuestionAuthor) {
+		t.Errorf("expected the role to be removed from custom claims, got %v", record.CustomClaims)
+	}
+}
+
+func TestDemoteAndSuspend(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	moderatorUID := h.signUp("moderator@example.com", "moderator-password")
+
+	ctx := context.Background()
+	moderator, err := h.store.Users().FindByUID(ctx, moderatorUID)
+	if err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	userPath := fmt.Sprintf("/api/v1/admin/users/%d", moderator.ID)
+	h.expect(h.do(http.MethodPost, userPath+"/roles", h.token(adminUID), gin.H{"role": "moderator"}), http.StatusOK)
+
+	session, err := h.provider.SignIn(ctx, "moderator@example.com", "moderator-password")
+	if err != nil {
+		t.Fatalf("signing in: %v", err)
+	}
+	h.expect(h.do(http.MethodPost, userPath+"/demote", h.token(adminUID), nil), http.StatusOK)
+	if _, err := h.provider.RefreshIDToken(ctx, session.RefreshToken); err == nil {
+		t.Error("expected demotion to revoke the refresh token")
+	}
+
+	h.expect(h.do(http.MethodPost, userPath+"/suspend", h.token(adminUID), gin.H{"reason": "spam"}), http.StatusOK)
+	h.expect(h.do(http.MethodGet, "/api/v1/verify", h.token(moderatorUID), nil), http.StatusForbidden)
+	record, err := h.provider.GetUser(ctx, moderatorUID)
+	if err != nil {
+		t.Fatalf("loading provider record: %v", err)
+	}
+	if !record.Disabled {
+		t.Error("expected the suspended account to be disabled")
+	}
+	// Repeating the request re-applies the provider state and reports the existing suspension
+	h.expect(h.do(http.MethodPost, userPath+"/suspend", h.token(adminUID), gin.H{"reason": "spam"}), http.StatusConflict)
+
+	h.expect(h.do(http.MethodPost, userPath+"/unsuspend", h.token(adminUID), nil), http.StatusOK)
+	if record, err = h.provider.GetUser(ctx, moderatorUID); err != nil || record.Disabled {
+		t.Errorf("expected the account to be re-enabled, got %+v, %v", record, err)
+	}
+}
+
+func TestQuestionGenerationAndRetrieval(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	playerUID := h.signUp("player@example.com", "player-password")
+
+	generate := gin.H{"category": "algebra", "difficulty": "beginner"}
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(playerUID), generate), http.StatusForbidden)
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(adminUID), gin.H{"category": "algebra", "difficulty": "trivial"}), http.StatusBadRequest)
+	if calls := h.generator.Calls(); len(calls) != 0 {
+		t.Fatalf("expected rejected requests not to reach the generator, got %v", calls)
+	}
+
+	resp := h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(adminUID), generate)
+	h.expect(resp, http.StatusCreated)
+	if calls := h.generator.Calls(); len(calls) != 1 || calls[0] != "algebra/beginner" {
+		t.Fatalf("expected one generator call for algebra/beginner, got %v", calls)
+	}
+	question, _ := resp.Body["question"].(map[string]interface{})
+	questionID, _ := question["QuestionID"].(string)
+	if questionID == "" {
+		t.Fatalf("created question has no ID: %v", resp.Body)
+	}
+
+	// Any signed-in user can read questions
+	token := h.token(playerUID)
+	resp = h.do(http.MethodGet, "/api/v1/questions", token, nil)
+	h.expect(resp, http.StatusOK)
+	if count, _ := resp.Body["count"].(float64); count != 1 {
+		t.Errorf("expected one question, got %v", resp.Body["count"])
+	}
+
+	resp = h.do(http.MethodGet, "/api/v1/questions/"+questionID, token, nil)
+	h.expect(resp, http.StatusOK)
+	if resp.Body["Answer"] != "100" || resp.Body["Category"] != "algebra" {
+		t.Errorf("unexpected question: %v", resp.Body)
+	}
+
+	resp = h.do(http.MethodGet, "/api/v1/questions/category/algebra", token, nil)
+	h.expect(resp, http.StatusOK)
+	if count, _ := resp.Body["count"].(float64); count != 1 {
+		t.Errorf("expected one algebra question, got %v", resp.Body["count"])
+	}
+	resp = h.do(http.MethodGet, "/api/v1/questions/category/geometry", token, nil)
+	h.expect(resp, http.StatusOK)
+	if count, _ := resp.Body["count"].(float64); count != 0 {
+		t.Errorf("expected no geometry questions, got %v", resp.Body["count"])
+	}
+
+	h.expect(h.do(http.MethodGet, "/api/v1/questions/missing", token, nil), http.StatusNotFound)
+	h.expect(h.do(http.MethodGet, "/api/v1/questions", "", nil), http.StatusUnauthorized)
+}
+
+func TestQuestionGenerationFailure(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	h.generator.fail = true
+
+	resp := h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(adminUID), gin.H{"category": "algebra", "difficulty": "expert"})
+	h.expect(resp, http.StatusInternalServerError)
+
+	questions, err := h.store.Questions().List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("listing questions: %v", err)
+	}
+	if len(questions) != 0 {
+		t.Errorf("expected nothing saved after a failed generation, got %d questions", len(questions))
+	}
+}
+
+func TestQuestionGenerationDisabled(t *testing.T) {
+	h := newHarness(t)
+	adminUID := h.bootstrapAdmin("admin@example.com")
+	h.generator.disabled = true
+
+	resp := h.do(http.MethodPost, "/api/v1/admin/questions/generate", h.token(adminUID), gin.H{"category": "algebra", "difficulty": "expert"})
+	h.expect(resp, http.StatusServiceUnavailable)
+
+	// The rest of the server keeps working and readiness only reports it degraded
+	resp = h.do(http.MethodGet, "/api/v1/health/ready", "", nil)
+	h.expect(resp, http.StatusOK)
+	if resp.Body["status"] != "degraded" {
+		t.Errorf("expected degraded with generation disabled, got %v", resp.Body)
+	}
+	h.expect(h.do(http.MethodGet, "/api/v1/questions", h.token(adminUID), nil), http.StatusOK)
+}
+
+func TestHealthChecks(t *testing.T) {
+	h := newHarness(t)
+	h.expect(h.do(http.MethodGet, "/api/v1/health/live", "", nil), http.StatusOK)
+
+	resp := h.do(http.MethodGet, "/api/v1/health/ready", "", nil)
+	h.expect(resp, http.StatusOK)
+	if resp.Body["status"] != "ready" {
+		t.Errorf("expected ready, got %v", resp.Body)
+	}
+
+	// An optional component only degrades readiness
+	h.generator.fail = true
+	resp = h.do(http.MethodGet, "/api/v1/health/ready", "", nil)
+	h.expect(resp, http.StatusOK)
+	components, _ := resp.Body["components"].(map[string]interface{})
+	generator, _ := components["generator"].(map[string]interface{})
+	if resp.Body["status"] != "degraded" || generator["status"] != "down" || generator["error"] == nil {
+		t.Errorf("expected degraded with the generator down, got %v", resp.Body)
+	}
+
+	h.databaseErr = errors.New("connection refused")
+	resp = h.do(http.MethodGet, "/api/v1/health/ready", "", nil)
+	h.expect(resp, http.StatusServiceUnavailable)
+	if resp.Body["status"] != "unready" {
+		t.Errorf("expected unready with the database down, got %v", resp.Body)
+	}
+	// Liveness ignores dependencies
+	h.expect(h.do(http.MethodGet, "/api/v1/health/live", "", nil), http.StatusOK)
+
+	h.databaseErr = nil
+	h.generator.fail = false
+	h.checker.SetDraining()
+	resp = h.do(http.MethodGet, "/api/v1/health/ready", "", nil)
+	h.expect(resp, http.StatusServiceUnavailable)
+	if resp.Body["draining"] != true {
+		t.Errorf("expected draining to be reported, got %v", resp.Body)
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	h := newHarness(t)
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	logging.Setup(&logs, slog.LevelDebug, "json")
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health/live", nil)
+	req.Header.Set("X-Request-ID", "trace-abc.123")
+	resp := h.send(req)
+	if id := resp.Header.Get("X-Request-ID"); id != "trace-abc.123" {
+		t.Errorf("expected the caller's request ID to be echoed, got %q", id)
+	}
+
+	// Malformed IDs are replaced rather than logged
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/health/live", nil)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	resp = h.send(req)
+	generated := resp.Header.Get("X-Request-ID")
+	if generated == "" || strings.Contains(generated, " ") {
+		t.Errorf("expected a generated request ID, got %q", generated)
+	}
+
+	found := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		if entry["msg"] == "Request" {
+			id, _ := entry["request_id"].(string)
+			found[id] = true
+		}
+	}
+	if !found["trace-abc.123"] || !found[generated] {
+		t.Errorf("expected access log entries with both request IDs, got %s", logs.String())
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := newHarness(t)
+
+	signups := testutil.ToFloat64(metrics.Signups.WithLabelValues("password"))
+	requests := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodPost, "/api/v1/auth/signup", "201"))
+	h.signUp("metrics@example.com", "password123")
+	if got := testutil.ToFloat64(metrics.Signups.WithLabelValues("password")) - signups; got != 1 {
+		t.Errorf("expected one signup to be counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodPost, "/api/v1/auth/signup", "201")) - requests; got != 1 {
+		t.Errorf("expected one signup request to be counted, got %v", got)
+	}
+
+	// Unknown paths share one label value instead of creating a series each
+	h.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/path/12345", nil))
+	if testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")) == 0 {
+		t.Error("expected unmatched requests to be counted under the unmatched route")
+	}
+
+	// Metrics are served by their own listener, never by the public router
+	rec := httptest.NewRecorder()
+	h.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected /metrics to be missing from the public router, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 from the metrics handler, got %d", rec.Code)
+	}
+	for _, name := range []string{"http_requests_total", "http_request_duration_seconds", "signups_total", "go_goroutines"} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Errorf("expected %s in /metrics output", name)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	h := newHarness(t)
+	uid := h.signUp("traced@example.com", "password123")
+	h.expect(h.do(http.MethodGet, "/api/v1/questions", h.token(uid), nil), http.StatusOK)
+	h.expect(h.do(http.MethodGet, "/api/v1/health/live", "", nil), http.StatusOK)
+
+	names := map[string]bool{}
+	for _, span := range exporter.GetSpans() {
+		names[span.Name] = true
+	}
+	if !names["/api/v1/auth/signup"] || !names["/api/v1/questions"] {
+		t.Errorf("expected spans named after the routes, got %v", names)
+	}
+	if names["/api/v1/health/live"] {
+		t.Error("expected health probes not to be traced")
+	}
+}
+
+func containsRole(roles []rbac.Role, role rbac.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
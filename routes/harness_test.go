@@ -0,0 +1,215 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/health"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/mailer"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/routes"
+	"github.com/gin-gonic/gin"
+)
+
+// harness serves the real router against an in-memory store, the local identity
+// provider (which issues test tokens without Firebase) and a fake question generator
+type harness struct {
+	t         *testing.T
+	router    *gin.Engine
+	store     repository.Store
+	provider  *identity.LocalProvider
+	mailer    *mailer.CaptureSender
+	generator *fakeGenerator
+	checker   *health.Checker
+	// databaseErr is what the readiness check reports for the store
+	databaseErr error
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	config.Current = config.Default()
+
+	provider, err := identity.NewLocalProvider([]byte(strings.Repeat("test-secret-", 4)), "")
+	if err != nil {
+		t.Fatalf("creating identity provider: %v", err)
+	}
+
+	h := &harness{
+		t:         t,
+		router:    gin.New(),
+		store:     repository.NewMemoryStore(),
+		provider:  provider,
+		mailer:    mailer.NewCaptureSender(false),
+		generator: &fakeGenerator{},
+	}
+	h.checker = health.NewChecker(time.Second,
+		health.Check{Name: "database", Critical: true, Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, h.databaseErr
+		}},
+		health.Check{Name: "generator", Run: func(ctx context.Context) (map[string]interface{}, error) {
+			if h.generator.disabled {
+				return nil, config.ErrGeneratorDisabled
+			}
+			if h.generator.fail {
+				return nil, errors.New("generator unavailable")
+			}
+			return nil, nil
+		}},
+	)
+	routes.InitializeRoutes(h.router, h.provider, h.mailer, h.store, h.generator, h.checker)
+	return h
+}
+
+// response is a recorded reply with its JSON body decoded
+type response struct {
+	Code   int
+	Header http.Header
+	Body   map[string]interface{} // decoded JSON responses
+	Text   string                 // any other response
+}
+
+// do sends a request through the router. token may be empty for public routes;
+// body, when not nil, is sent as JSON.
+func (h *harness) do(method, path, token string, body interface{}) response {
+	h.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("encoding request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return h.send(req)
+}
+
+// send serves a prepared request
+func (h *harness) send(req *http.Request) response {
+	h.t.Helper()
+	rec := httptest.NewRecorder()
+	h.router.ServeHTTP(rec, req)
+
+	resp := response{Code: rec.Code, Header: rec.Header()}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		resp.Text = rec.Body.String()
+	} else if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+			h.t.Fatalf("%s %s: decoding response %q: %v", req.Method, req.URL.Path, rec.Body.String(), err)
+		}
+	}
+	return resp
+}
+
+// expect fails the test unless the response has the given status
+func (h *harness) expect(resp response, code int) {
+	h.t.Helper()
+	if resp.Code != code {
+		h.t.Fatalf("expected status %d, got %d: %v", code, resp.Code, resp.Body)
+	}
+}
+
+// signUp registers an account through the API and returns its UID
+func (h *harness) signUp(email, password string) string {
+	h.t.Helper()
+	resp := h.do(http.MethodPost, "/api/v1/auth/signup", "", gin.H{"email": email, "password": password})
+	h.expect(resp, http.StatusCreated)
+
+	user, _ := resp.Body["user"].(map[string]interface{})
+	uid, _ := user["uid"].(string)
+	if uid == "" {
+		h.t.Fatalf("signup response has no uid: %v", resp.Body)
+	}
+	return uid
+}
+
+// token issues a fresh ID token for the account, carrying its current custom claims
+func (h *harness) token(uid string) string {
+	h.t.Helper()
+	token, err := h.provider.IssueIDToken(uid)
+	if err != nil {
+		h.t.Fatalf("issuing token for %s: %v", uid, err)
+	}
+	return token
+}
+
+// bootstrapAdmin signs up an account and grants it the admin role directly in the
+// store, as an operator would for the first admin, returning its UID
+func (h *harness) bootstrapAdmin(email string) string {
+	h.t.Helper()
+	uid := h.signUp(email, "admin-password")
+
+	ctx := context.Background()
+	user, err := h.store.Users().FindByUID(ctx, uid)
+	if err != nil {
+		h.t.Fatalf("loading admin: %v", err)
+	}
+	if err := h.store.Users().GrantRole(ctx, user.ID, user.ID, rbac.RoleAdmin); err != nil {
+		h.t.Fatalf("granting admin: %v", err)
+	}
+	return uid
+}
+
+// fakeGenerator stands in for Gemini, returning a canned question per call
+type fakeGenerator struct {
+	mu       sync.Mutex
+	calls    []string // "category/difficulty" per call
+	fail     bool
+	disabled bool // as if GEMINI_API_KEY were not set
+}
+
+func (g *fakeGenerator) GenerateQuestion(ctx context.Context, category, difficulty string) (map[string]interface{}, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls = append(g.calls, category+"/"+difficulty)
+	if g.disabled {
+		return nil, config.ErrGeneratorDisabled
+	}
+	if g.fail {
+		return nil, errors.New("generator unavailable")
+	}
+
+	// Decoded from JSON like the real response, so numbers are float64
+	return map[string]interface{}{
+		"title":    "Sum of the first n odd numbers",
+		"question": "What is 1 + 3 + 5 + ... + 19?",
+		"solution": map[string]interface{}{
+			"answer":      "100",
+			"explanation": "The first n odd numbers sum to n squared, and there are 10 terms.",
+		},
+		"hints":        []interface{}{"Try small cases", "Look for squares"},
+		"difficulty":   difficulty,
+		"expectedTime": float64(5),
+		"points":       float64(50),
+		"category":     category,
+		"tags":         []interface{}{"series"},
+	}, nil
+}
+
+func (g *fakeGenerator) Calls() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.calls...)
+}
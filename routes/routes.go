@@ -1,70 +1,159 @@
 package routes
 
 import (
-	"context"
-	"log"
+	"net/http"
+	"strings"
 
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/config"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/controllers"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/health"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/identity"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/mailer"
 	"github.com/ThinkBattleground/ThinkBattleground-Backend/middleware"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/rbac"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/repository"
+	"github.com/ThinkBattleground/ThinkBattleground-Backend/tracing"
 
-	firebase "firebase.google.com/go/v4"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func InitializeRoutes(router *gin.Engine, app *firebase.App) {
-	authClient, err := app.Auth(context.Background())
-	if err != nil {
-		log.Fatalf("error getting Auth client: %v\n", err)
-		return
-	}
-
+func InitializeRoutes(router *gin.Engine, provider identity.IdentityProvider, sender mailer.Sender, store repository.Store, generator config.QuestionGenerator, checker *health.Checker) {
 	// Initialize controllers
-	authController := controllers.NewAuthController(authClient)
-	questionController := &controllers.QuestionController{}
+	healthController := controllers.NewHealthController(checker)
+
+	// Every request gets a trace span, an ID for its log entries, one access log line,
+	// metrics, and panic recovery. Probes are not traced.
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+			return !strings.HasPrefix(r.URL.Path, "/api/v1/health")
+		})),
+		middleware.RequestID(),
+		middleware.AccessLog(),
+		middleware.Metrics(),
+		middleware.Recovery(),
+	)
+
+	authController := controllers.NewAuthController(provider, sender, store)
+	questionController := controllers.NewQuestionController(store, generator)
+	contestController := controllers.NewContestController(store)
+	roomController := controllers.NewRoomController(store)
+	achievementController := controllers.NewAchievementController(store)
+	friendController := &controllers.FriendController{}
+	profileController := controllers.NewProfileController(store)
 
 	// Public routes
 	public := router.Group("/api/v1")
 	{
-		public.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{
-				"status": "OK",
-			})
-		})
+		// Health checks for load balancers and container orchestrators
+		public.GET("/health", healthController.Live)
+		public.GET("/health/live", healthController.Live)
+		public.GET("/health/ready", healthController.Ready)
 
 		// Auth routes
 		public.GET("/auth/methods", authController.GetAuthMethods)
 		public.POST("/auth/signup", authController.SignUp)
 		public.POST("/auth/signin", authController.SignIn)
+		public.POST("/auth/refresh", authController.RefreshToken)
 		public.POST("/auth/forgot-password", authController.ForgotPassword)
+		public.GET("/auth/verify-email", authController.ShowEmailConfirmation)
+		public.POST("/auth/verify-email", authController.ConfirmEmail)
 		public.GET("/auth/google", authController.InitiateGoogleSignIn)
 		public.GET("/auth/google/callback", authController.HandleGoogleCallback)
 	}
 
 	// Protected routes
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(authClient))
+	protected.Use(middleware.AuthMiddleware(provider, store.Users()))
 	{
 		protected.GET("/verify", authController.VerifyToken)
 		protected.GET("/profile", authController.GetUserProfile)
+		protected.POST("/auth/verify-email/send", authController.SendVerificationEmail)
+		protected.GET("/auth/sessions", authController.ListSessions)
+		protected.DELETE("/auth/sessions", authController.RevokeAllSessions)
+		protected.DELETE("/auth/sessions/:id", authController.RevokeSession)
 		protected.PUT("/users/profile", authController.UpdateUserProfile)
+		protected.DELETE("/users/me", authController.DeleteAccount)
+		protected.GET("/users/me/export", authController.ExportAccountData)
+		protected.PUT("/users/privacy", profileController.UpdatePrivacySettings)
+		protected.GET("/users/:id", profileController.GetPublicProfile)
+		protected.GET("/achievements", achievementController.ListAchievements)
 
 		// Question routes
 		protected.GET("/questions", questionController.ListQuestions)
 		protected.GET("/questions/:id", questionController.GetQuestion)
 		protected.GET("/questions/category/:category", questionController.GetQuestionsByCategory)
+
+		// Contest routes
+		protected.GET("/contests", contestController.ListContests)
+		protected.GET("/contests/:id", contestController.GetContest)
+		protected.POST("/contests/:id/register", contestController.RegisterForContest)
+		protected.POST("/contests/:id/submit", contestController.SubmitContestAnswer)
+		protected.GET("/contests/:id/scoreboard", contestController.GetScoreboard)
+
+		// Private room routes
+		protected.POST("/rooms", roomController.CreateRoom)
+		protected.POST("/rooms/join", roomController.JoinRoom)
+		protected.GET("/rooms/:code", roomController.GetRoom)
+		protected.POST("/rooms/:code/leave", roomController.LeaveRoom)
+		protected.POST("/rooms/:code/start", roomController.StartRoom)
+		protected.POST("/rooms/:code/answer", roomController.SubmitRoomAnswer)
+		protected.GET("/rooms/:code/results", roomController.GetRoomResults)
+
+		// Social routes
+		protected.GET("/friends", friendController.ListFriends)
+		protected.GET("/friends/requests", friendController.ListFriendRequests)
+		protected.POST("/friends/requests", friendController.SendFriendRequest)
+		protected.POST("/friends/requests/:id/accept", friendController.AcceptFriendRequest)
+		protected.POST("/friends/requests/:id/decline", friendController.DeclineFriendRequest)
+		protected.DELETE("/friends/:id", friendController.RemoveFriend)
+		protected.GET("/friends/leaderboard", friendController.FriendLeaderboard)
+		protected.POST("/friends/:id/challenge", friendController.ChallengeFriend)
+		protected.GET("/friends/challenges", friendController.ListChallenges)
+		protected.GET("/blocks", friendController.ListBlockedUsers)
+		protected.POST("/blocks", friendController.BlockUser)
+		protected.DELETE("/blocks/:id", friendController.UnblockUser)
 	}
 
-	// Admin routes
-	adminController := &controllers.AdminController{}
+	// Admin routes; each route requires the permission for its operation
+	adminController := controllers.NewAdminController(provider, store)
+	roleController := controllers.NewRoleController(provider, store)
+	auditController := &controllers.AuditController{}
 	admin := router.Group("/api/v1/admin")
-	admin.Use(middleware.AuthMiddleware(authClient))
-	admin.Use(middleware.AdminMiddleware())
+	admin.Use(middleware.AuthMiddleware(provider, store.Users()))
 	{
-		admin.POST("/users/make-admin", adminController.MakeUserAdmin)
-		admin.GET("/users", adminController.ListUsers)
+		moderateUsers := middleware.RequirePermission(store.Users(), rbac.PermissionModerateUsers)
+		manageRoles := middleware.RequirePermission(store.Users(), rbac.PermissionManageRoles)
+		createQuestions := middleware.RequirePermission(store.Users(), rbac.PermissionCreateQuestions)
+		manageContests := middleware.RequirePermission(store.Users(), rbac.PermissionManageContests)
+		readAudit := middleware.RequirePermission(store.Users(), rbac.PermissionReadAudit)
+
+		admin.POST("/users/make-admin", manageRoles, adminController.MakeUserAdmin)
+		admin.GET("/users", moderateUsers, adminController.ListUsers)
+		admin.POST("/users/:id/suspend", moderateUsers, adminController.SuspendUser)
+		admin.POST("/users/:id/unsuspend", moderateUsers, adminController.UnsuspendUser)
+		admin.POST("/users/:id/demote", manageRoles, adminController.DemoteUser)
+
+		// Role management
+		admin.GET("/roles", manageRoles, roleController.ListRoles)
+		admin.GET("/roles/changes", manageRoles, roleController.ListRoleChanges)
+		admin.GET("/users/:id/roles", manageRoles, roleController.GetUserRoles)
+		admin.POST("/users/:id/roles", manageRoles, roleController.GrantRole)
+		admin.DELETE("/users/:id/roles/:role", manageRoles, roleController.RevokeRole)
+
+		// Audit log
+		admin.GET("/audit", readAudit, auditController.ListAuditLog)
 
 		// Question management
-		admin.POST("/questions/generate", questionController.CreateQuestionWithGemini)
-		admin.POST("/questions/create", questionController.CreateManualQuestion)
+		admin.POST("/questions/generate", createQuestions, questionController.CreateQuestionWithGemini)
+		admin.POST("/questions/create", createQuestions, questionController.CreateManualQuestion)
+
+		// Contest management
+		admin.POST("/contests", manageContests, contestController.CreateContest)
+		admin.GET("/contests", manageContests, contestController.ListContests)
+		admin.GET("/contests/:id", manageContests, contestController.GetContestAdmin)
+		admin.PUT("/contests/:id", manageContests, contestController.UpdateContest)
+		admin.DELETE("/contests/:id", manageContests, contestController.DeleteContest)
+		admin.GET("/contests/:id/scoreboard", manageContests, contestController.GetLiveScoreboard)
 	}
 }
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// redacted replaces secret values in redacted output
+const redacted = "[REDACTED]"
+
+// minRedactLength skips values too short to mask without mangling unrelated text
+const minRedactLength = 4
+
+// Redactor masks known secret values in text
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor creates a Redactor for the given values. Empty and very short values are ignored.
+func NewRedactor(values ...string) *Redactor {
+	var kept []string
+	for _, value := range values {
+		if len(value) >= minRedactLength {
+			kept = append(kept, value)
+		}
+	}
+	// Longest first, so a secret containing another is masked whole
+	sort.Slice(kept, func(i, j int) bool { return len(kept[i]) > len(kept[j]) })
+
+	pairs := make([]string, 0, 2*len(kept))
+	for _, value := range kept {
+		pairs = append(pairs, value, redacted)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redact returns s with every secret replaced
+func (r *Redactor) Redact(s string) string {
+	return r.replacer.Replace(s)
+}
+
+// Writer wraps w so everything written through it is redacted. Loggers write each
+// entry in one call, so secrets are never split across writes.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &redactingWriter{redactor: r, w: w}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	w        io.Writer
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source looks secrets up by name, such as GEMINI_API_KEY
+type Source interface {
+	// Lookup returns the secret and whether the source has it
+	Lookup(name string) (string, bool, error)
+}
+
+type envSource struct{}
+
+// Env reads secrets from environment variables of the same name
+func Env() Source {
+	return envSource{}
+}
+
+func (envSource) Lookup(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	return value, value != "", nil
+}
+
+type fileSource struct{}
+
+// File reads each secret from the file named by the NAME_FILE environment variable,
+// the convention for Docker and Compose secrets
+func File() Source {
+	return fileSource{}
+}
+
+func (fileSource) Lookup(name string) (string, bool, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), true, nil
+}
+
+type dirSource struct {
+	dir string
+}
+
+// Dir reads secrets from files in a mounted directory such as /run/secrets or a
+// Kubernetes secret volume. The file is named after the secret, in upper or lower case.
+// A missing directory holds no secrets.
+func Dir(dir string) Source {
+	return dirSource{dir: dir}
+}
+
+func (s dirSource) Lookup(name string) (string, bool, error) {
+	if s.dir == "" {
+		return "", false, nil
+	}
+	for _, filename := range []string{name, strings.ToLower(name)} {
+		data, err := os.ReadFile(filepath.Join(s.dir, filename))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	return "", false, nil
+}
+
+type mapSource map[string]string
+
+// Map serves secrets from already loaded values, such as a development .env file
+func Map(values map[string]string) Source {
+	return mapSource(values)
+}
+
+func (s mapSource) Lookup(name string) (string, bool, error) {
+	value, ok := s[name]
+	return value, ok && value != "", nil
+}
+
+type chain []Source
+
+// Chain returns the secret from the first source that has it
+func Chain(sources ...Source) Source {
+	return chain(sources)
+}
+
+func (c chain) Lookup(name string) (string, bool, error) {
+	for _, source := range c {
+		value, ok, err := source.Lookup(name)
+		if err != nil || ok {
+			return value, ok, err
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// GormPlugin records a client span for every query GORM runs, as a child of the span
+// in the context passed to WithContext. Spans carry the SQL with its placeholders,
+// never the bound values.
+type GormPlugin struct{}
+
+// NewGormPlugin creates a GormPlugin; install it with db.Use
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before", p.before("INSERT")),
+		cb.Create().After("gorm:create").Register("tracing:after", p.after("INSERT")),
+		cb.Query().Before("gorm:query").Register("tracing:before", p.before("SELECT")),
+		cb.Query().After("gorm:query").Register("tracing:after", p.after("SELECT")),
+		cb.Update().Before("gorm:update").Register("tracing:before", p.before("UPDATE")),
+		cb.Update().After("gorm:update").Register("tracing:after", p.after("UPDATE")),
+		cb.Delete().Before("gorm:delete").Register("tracing:before", p.before("DELETE")),
+		cb.Delete().After("gorm:delete").Register("tracing:after", p.after("DELETE")),
+		cb.Row().Before("gorm:row").Register("tracing:before", p.before("ROW")),
+		cb.Row().After("gorm:row").Register("tracing:after", p.after("ROW")),
+		cb.Raw().Before("gorm:raw").Register("tracing:before", p.before("RAW")),
+		cb.Raw().After("gorm:raw").Register("tracing:after", p.after("RAW")),
+	)
+}
+
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, _ := Tracer.Start(db.Statement.Context, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+		db.Statement.Context = ctx
+	}
+}
+
+func (p *GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		span := trace.SpanFromContext(db.Statement.Context)
+		if !span.IsRecording() {
+			return
+		}
+		defer span.End()
+
+		// The table is only known once GORM has parsed the statement
+		if db.Statement.Table != "" {
+			span.SetName(operation + " " + db.Statement.Table)
+			span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+		}
+		span.SetAttributes(
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			RecordError(span, db.Error)
+		}
+	}
+}